@@ -0,0 +1,226 @@
+// Package assert checks HTTP responses: status codes and classes, headers,
+// JSON path values, the body, response time and JSON Schema.
+package assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-rest-api-cli-demo/internal/jsonpath"
+	"go-rest-api-cli-demo/internal/jsonschema"
+)
+
+// Response is the part of an HTTP response assertions look at.
+type Response struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	Duration time.Duration
+
+	decoded bool
+	doc     interface{}
+	docErr  error
+}
+
+// JSON decodes the body once.
+func (r *Response) JSON() (interface{}, error) {
+	if !r.decoded {
+		r.decoded = true
+		if err := json.Unmarshal(r.Body, &r.doc); err != nil {
+			r.docErr = fmt.Errorf("response body is not JSON: %w", err)
+		}
+	}
+	return r.doc, r.docErr
+}
+
+// Assertion is one expectation about a response.
+type Assertion interface {
+	// Check returns why the response does not meet the expectation, or nil.
+	Check(r *Response) error
+	// String describes the expectation, e.g. "status 2xx".
+	String() string
+}
+
+// Failure is an assertion that did not hold.
+type Failure struct {
+	Assertion string
+	Message   string
+}
+
+func (f Failure) String() string {
+	return f.Assertion + ": " + f.Message
+}
+
+// Check runs every assertion and returns the failures.
+func Check(r *Response, assertions []Assertion) []Failure {
+	var out []Failure
+	for _, a := range assertions {
+		if err := a.Check(r); err != nil {
+			out = append(out, Failure{Assertion: a.String(), Message: err.Error()})
+		}
+	}
+	return out
+}
+
+// statusRange is an inclusive range of status codes.
+type statusRange struct{ lo, hi int }
+
+type statusAssertion struct {
+	spec   string
+	ranges []statusRange
+}
+
+// Status parses a status expectation: codes, classes and ranges separated
+// by commas, e.g. "200", "2xx", "201,204" or "200-299,304".
+func Status(spec string) (Assertion, error) {
+	a := &statusAssertion{spec: spec}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		switch {
+		case part == "":
+			continue
+		case len(part) == 3 && strings.HasSuffix(part, "xx") && part[0] >= '1' && part[0] <= '5':
+			base := int(part[0]-'0') * 100
+			a.ranges = append(a.ranges, statusRange{base, base + 99})
+		case strings.Contains(part, "-"):
+			lo, hi, _ := strings.Cut(part, "-")
+			l, err1 := strconv.Atoi(strings.TrimSpace(lo))
+			h, err2 := strconv.Atoi(strings.TrimSpace(hi))
+			if err1 != nil || err2 != nil || l > h {
+				return nil, fmt.Errorf("invalid status range %q", part)
+			}
+			a.ranges = append(a.ranges, statusRange{l, h})
+		default:
+			code, err := strconv.Atoi(part)
+			if err != nil || code < 100 || code > 599 {
+				return nil, fmt.Errorf("invalid status %q (want a code, 2xx or 200-299)", part)
+			}
+			a.ranges = append(a.ranges, statusRange{code, code})
+		}
+	}
+	if len(a.ranges) == 0 {
+		return nil, fmt.Errorf("empty status expectation")
+	}
+	return a, nil
+}
+
+func (a *statusAssertion) Check(r *Response) error {
+	for _, rg := range a.ranges {
+		if r.Status >= rg.lo && r.Status <= rg.hi {
+			return nil
+		}
+	}
+	return fmt.Errorf("got %d", r.Status)
+}
+
+func (a *statusAssertion) String() string { return "status " + a.spec }
+
+type headerAssertion struct {
+	name string
+	m    Matcher
+}
+
+// Header checks a response header (all values joined with ", ").
+func Header(name string, m Matcher) Assertion {
+	return &headerAssertion{name: http.CanonicalHeaderKey(name), m: m}
+}
+
+func (a *headerAssertion) Check(r *Response) error {
+	values, ok := r.Header[a.name]
+	var v interface{}
+	if ok {
+		v = strings.Join(values, ", ")
+	}
+	return a.m.Match(v, ok)
+}
+
+func (a *headerAssertion) String() string { return "header " + a.name + " " + a.m.String() }
+
+type jsonAssertion struct {
+	path string
+	m    Matcher
+}
+
+// JSON checks the value at a JSON path of the body. Paths that can match
+// several values (wildcards, filters) check the list of matches.
+func JSON(path string, m Matcher) Assertion {
+	return &jsonAssertion{path: path, m: m}
+}
+
+func (a *jsonAssertion) Check(r *Response) error {
+	doc, err := r.JSON()
+	if err != nil {
+		return err
+	}
+	if jsonpath.IsDefinite(a.path) {
+		v, err := jsonpath.Get(doc, a.path)
+		return a.m.Match(v, err == nil)
+	}
+	matches, err := jsonpath.Query(doc, a.path)
+	if err != nil {
+		return err
+	}
+	return a.m.Match(matches, len(matches) > 0)
+}
+
+func (a *jsonAssertion) String() string { return a.path + " " + a.m.String() }
+
+type bodyAssertion struct{ m Matcher }
+
+// Body checks the body as text.
+func Body(m Matcher) Assertion { return &bodyAssertion{m: m} }
+
+func (a *bodyAssertion) Check(r *Response) error {
+	return a.m.Match(string(r.Body), true)
+}
+
+func (a *bodyAssertion) String() string { return "body " + a.m.String() }
+
+type timeAssertion struct{ max time.Duration }
+
+// MaxTime checks that the response arrived within max.
+func MaxTime(max time.Duration) Assertion { return &timeAssertion{max: max} }
+
+func (a *timeAssertion) Check(r *Response) error {
+	if r.Duration > a.max {
+		return fmt.Errorf("took %d ms", r.Duration.Milliseconds())
+	}
+	return nil
+}
+
+func (a *timeAssertion) String() string { return "time <= " + a.max.String() }
+
+type schemaAssertion struct {
+	name   string
+	schema *jsonschema.Schema
+}
+
+// Schema checks the JSON body against a JSON Schema; name labels it.
+func Schema(name string, s *jsonschema.Schema) Assertion {
+	return &schemaAssertion{name: name, schema: s}
+}
+
+func (a *schemaAssertion) Check(r *Response) error {
+	doc, err := r.JSON()
+	if err != nil {
+		return err
+	}
+	errs, err := a.schema.Validate(doc)
+	if err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	parts := make([]string, 0, len(errs))
+	for _, e := range errs {
+		parts = append(parts, e.String())
+	}
+	return fmt.Errorf("%s", strings.Join(parts, "; "))
+}
+
+func (a *schemaAssertion) String() string { return "schema " + a.name }
@@ -0,0 +1,203 @@
+package assert
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		spec string
+		code int
+		ok   bool
+	}{
+		{"200", 200, true},
+		{"200", 201, false},
+		{"2xx", 204, true},
+		{"2XX", 301, false},
+		{"201,204", 204, true},
+		{"201, 204", 200, false},
+		{"200-299,304", 304, true},
+		{"200-299,304", 302, false},
+		{"4xx,5xx", 503, true},
+	}
+	for _, tt := range tests {
+		a, err := Status(tt.spec)
+		if err != nil {
+			t.Fatalf("Status(%q): %v", tt.spec, err)
+		}
+		if err := a.Check(&Response{Status: tt.code}); (err == nil) != tt.ok {
+			t.Errorf("Status(%q).Check(%d) = %v, want ok=%v", tt.spec, tt.code, err, tt.ok)
+		}
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	for _, spec := range []string{"", ",", "abc", "99", "600", "6xx", "299-200", "2xx-"} {
+		if _, err := Status(spec); err == nil {
+			t.Errorf("Status(%q): want an error", spec)
+		}
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string // matcher spec as JSON
+		value   string // actual value as JSON; empty means absent
+		ok      bool
+		message string // substring of the failure message
+	}{
+		{"equal number", `42`, `42`, true, ""},
+		{"equal string", `"ok"`, `"ok"`, true, ""},
+		{"string equals number", `"42"`, `42`, true, ""},
+		{"number equals string", `42`, `"42"`, true, ""},
+		{"not equal", `"ok"`, `"fail"`, false, `got "fail"`},
+		{"missing", `1`, ``, false, "missing"},
+		{"expected object", `{"a":1}`, `{"a":1}`, true, ""},
+		{"expected object differs", `{"a":1}`, `{"a":2}`, false, `got {"a":2}`},
+		{"equals operator", `{"equals":[1,2]}`, `[1,2]`, true, ""},
+		{"not_equals", `{"not_equals":0}`, `1`, true, ""},
+		{"not_equals same", `{"not_equals":0}`, `0`, false, "got 0"},
+		{"not_equals absent", `{"not_equals":0}`, ``, true, ""},
+		{"exists", `{"exists":true}`, `null`, true, ""},
+		{"exists absent", `{"exists":true}`, ``, false, "missing"},
+		{"does not exist", `{"exists":false}`, ``, true, ""},
+		{"does not exist present", `{"exists":false}`, `1`, false, "present: 1"},
+		{"contains substring", `{"contains":"ell"}`, `"hello"`, true, ""},
+		{"contains item", `{"contains":2}`, `[1,2,3]`, true, ""},
+		{"contains key", `{"contains":"id"}`, `{"id":1}`, true, ""},
+		{"contains missing item", `{"contains":4}`, `[1,2,3]`, false, "got [1,2,3]"},
+		{"matches", `{"matches":"^ab+c$"}`, `"abbc"`, true, ""},
+		{"matches number text", `{"matches":"^4\\d$"}`, `42`, true, ""},
+		{"matches fails", `{"matches":"^x"}`, `"abc"`, false, `got "abc"`},
+		{"range", `{"gt":0,"lte":10}`, `10`, true, ""},
+		{"range fails", `{"gt":0,"lte":10}`, `11`, false, "got 11"},
+		{"compare numeric text", `{"gte":100}`, `"512"`, true, ""},
+		{"length string", `{"length":3}`, `"héé"`, true, ""},
+		{"length array", `{"length":2}`, `[1]`, false, "got length 1"},
+		{"length of number", `{"length":1}`, `5`, false, "has no length"},
+		{"type integer", `{"type":"integer"}`, `3`, true, ""},
+		{"type number accepts integer", `{"type":"number"}`, `3`, true, ""},
+		{"type integer rejects fraction", `{"type":"integer"}`, `3.5`, false, "got number 3.5"},
+		{"type null", `{"type":"null"}`, `null`, true, ""},
+		{"type object", `{"type":"object"}`, `[]`, false, "got array"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec interface{}
+			if err := json.Unmarshal([]byte(tt.spec), &spec); err != nil {
+				t.Fatal(err)
+			}
+			m, err := ParseMatcher(spec)
+			if err != nil {
+				t.Fatalf("ParseMatcher(%s): %v", tt.spec, err)
+			}
+			var v interface{}
+			present := tt.value != ""
+			if present {
+				if err := json.Unmarshal([]byte(tt.value), &v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err = m.Match(v, present)
+			if (err == nil) != tt.ok {
+				t.Fatalf("%s matching %s: %v, want ok=%v", m, tt.value, err, tt.ok)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("%s matching %s: %q, want it to contain %q", m, tt.value, err, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseMatcherErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{`{"exists":"yes"}`, "exists: want true or false"},
+		{`{"matches":"("}`, "matches:"},
+		{`{"gt":"1"}`, "gt: want a number"},
+		{`{"length":1.5}`, "length: want a whole number"},
+		{`{"length":-1}`, "length: want a whole number"},
+		{`{"type":"text"}`, "type: want"},
+	}
+	for _, tt := range tests {
+		var spec interface{}
+		if err := json.Unmarshal([]byte(tt.spec), &spec); err != nil {
+			t.Fatal(err)
+		}
+		_, err := ParseMatcher(spec)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseMatcher(%s) error = %v, want %q", tt.spec, err, tt.want)
+		}
+	}
+}
+
+func TestExactly(t *testing.T) {
+	if err := Exactly("42").Match(42.0, true); err == nil {
+		t.Error(`Exactly("42") matched the number 42`)
+	}
+	if err := Exactly("42").Match("42", true); err != nil {
+		t.Errorf(`Exactly("42") on "42": %v`, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	resp := &Response{
+		Status:   201,
+		Header:   http.Header{"Content-Type": {"application/json"}, "X-Tags": {"a", "b"}},
+		Body:     []byte(`{"id":7,"items":[{"n":1},{"n":2}],"name":"Ada"}`),
+		Duration: 120 * time.Millisecond,
+	}
+	status, err := Status("2xx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertions := []Assertion{
+		status,
+		Header("content-type", contains{"json"}),
+		Header("X-Tags", Equals("a, b")),
+		Header("X-Missing", Exists(false)),
+		JSON("$.id", Equals(7.0)),
+		JSON("$.items[*].n", Equals([]interface{}{1.0, 2.0})),
+		JSON("$.missing", Exists(false)),
+		Body(contains{`"name":"Ada"`}),
+		MaxTime(time.Second),
+	}
+	if failures := Check(resp, assertions); len(failures) != 0 {
+		t.Fatalf("Check: unexpected failures %v", failures)
+	}
+
+	failing := []Assertion{
+		JSON("$.name", Equals("Bob")),
+		JSON("$.items[*].n", length(3)),
+		MaxTime(100 * time.Millisecond),
+	}
+	failures := Check(resp, failing)
+	want := []string{
+		`$.name == "Bob": got "Ada"`,
+		`$.items[*].n has length 3: got length 2`,
+		`time <= 100ms: took 120 ms`,
+	}
+	if len(failures) != len(want) {
+		t.Fatalf("Check: got %d failures %v, want %d", len(failures), failures, len(want))
+	}
+	for i, f := range failures {
+		if f.String() != want[i] {
+			t.Errorf("failure %d = %q, want %q", i, f, want[i])
+		}
+	}
+}
+
+func TestCheckNonJSONBody(t *testing.T) {
+	resp := &Response{Status: 200, Body: []byte("<html>")}
+	failures := Check(resp, []Assertion{JSON("$.id", Exists(true))})
+	if len(failures) != 1 || !strings.Contains(failures[0].Message, "not JSON") {
+		t.Errorf("Check on a non-JSON body = %v, want a 'not JSON' failure", failures)
+	}
+}
@@ -0,0 +1,382 @@
+package assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/jsonpath"
+)
+
+// Matcher checks one value (a header, a JSON path result, the body).
+// present is false when the value does not exist.
+type Matcher interface {
+	Match(v interface{}, present bool) error
+	String() string
+}
+
+// Operators are the keys of a matcher map, e.g. {gt: 0, lt: 100}.
+var Operators = []string{"equals", "not_equals", "exists", "contains", "matches", "gt", "gte", "lt", "lte", "length", "type"}
+
+// ParseMatcher builds a matcher from a decoded YAML/JSON value: a map whose
+// keys are all Operators combines them; anything else must be equal.
+func ParseMatcher(spec interface{}) (Matcher, error) {
+	ops, ok := spec.(map[string]interface{})
+	if !ok || len(ops) == 0 {
+		return Equals(spec), nil
+	}
+	for key := range ops {
+		if !isOperator(key) {
+			return Equals(spec), nil // an expected object
+		}
+	}
+
+	var all allOf
+	for _, key := range sortedKeys(ops) {
+		m, err := operator(key, ops[key])
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, m)
+	}
+	return all, nil
+}
+
+func isOperator(key string) bool {
+	for _, op := range Operators {
+		if op == key {
+			return true
+		}
+	}
+	return false
+}
+
+func operator(key string, arg interface{}) (Matcher, error) {
+	switch key {
+	case "equals":
+		return Equals(arg), nil
+	case "not_equals":
+		return notEquals{arg}, nil
+	case "exists":
+		want, ok := arg.(bool)
+		if !ok {
+			return nil, fmt.Errorf("exists: want true or false")
+		}
+		return Exists(want), nil
+	case "contains":
+		return contains{arg}, nil
+	case "matches":
+		s, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("matches: want a regular expression")
+		}
+		return Matches(s)
+	case "gt", "gte", "lt", "lte":
+		n, ok := arg.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s: want a number", key)
+		}
+		return compare{op: map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[key], n: n}, nil
+	case "length":
+		n, ok := arg.(float64)
+		if !ok || n < 0 || n != math.Trunc(n) {
+			return nil, fmt.Errorf("length: want a whole number")
+		}
+		return length(int(n)), nil
+	case "type":
+		s, _ := arg.(string)
+		switch s {
+		case "string", "number", "integer", "boolean", "array", "object", "null":
+			return typeIs(s), nil
+		}
+		return nil, fmt.Errorf("type: want string, number, integer, boolean, array, object or null")
+	}
+	return nil, fmt.Errorf("unknown operator %q", key)
+}
+
+type allOf []Matcher
+
+func (a allOf) Match(v interface{}, present bool) error {
+	for _, m := range a {
+		if err := m.Match(v, present); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a allOf) String() string {
+	parts := make([]string, len(a))
+	for i, m := range a {
+		parts[i] = m.String()
+	}
+	return strings.Join(parts, " and ")
+}
+
+type equals struct{ want interface{} }
+
+// Equals matches values equal to want. Text values (headers, the body)
+// also match numbers and booleans with the same text.
+func Equals(want interface{}) Matcher { return equals{want} }
+
+func (m equals) Match(v interface{}, present bool) error {
+	if !present {
+		return fmt.Errorf("missing")
+	}
+	if !sameValue(v, m.want) {
+		return fmt.Errorf("got %s", show(v))
+	}
+	return nil
+}
+
+func (m equals) String() string { return "== " + show(m.want) }
+
+type exactly struct{ want interface{} }
+
+// Exactly matches values equal to want with the same JSON type, so "42"
+// does not match 42.
+func Exactly(want interface{}) Matcher { return exactly{want} }
+
+func (m exactly) Match(v interface{}, present bool) error {
+	if !present {
+		return fmt.Errorf("missing")
+	}
+	if !reflect.DeepEqual(v, m.want) {
+		return fmt.Errorf("got %s", show(v))
+	}
+	return nil
+}
+
+func (m exactly) String() string { return "=== " + show(m.want) }
+
+type notEquals struct{ want interface{} }
+
+func (m notEquals) Match(v interface{}, present bool) error {
+	if present && sameValue(v, m.want) {
+		return fmt.Errorf("got %s", show(v))
+	}
+	return nil
+}
+
+func (m notEquals) String() string { return "!= " + show(m.want) }
+
+type exists bool
+
+// Exists matches present (true) or absent (false) values.
+func Exists(want bool) Matcher { return exists(want) }
+
+func (m exists) Match(v interface{}, present bool) error {
+	switch {
+	case bool(m) && !present:
+		return fmt.Errorf("missing")
+	case !bool(m) && present:
+		return fmt.Errorf("present: %s", show(v))
+	}
+	return nil
+}
+
+func (m exists) String() string {
+	if m {
+		return "exists"
+	}
+	return "does not exist"
+}
+
+type contains struct{ want interface{} }
+
+func (m contains) Match(v interface{}, present bool) error {
+	if !present {
+		return fmt.Errorf("missing")
+	}
+	switch t := v.(type) {
+	case string:
+		if strings.Contains(t, text(m.want)) {
+			return nil
+		}
+	case []interface{}:
+		for _, x := range t {
+			if sameValue(x, m.want) {
+				return nil
+			}
+		}
+	case map[string]interface{}:
+		if _, ok := t[text(m.want)]; ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("got %s", show(v))
+}
+
+func (m contains) String() string { return "contains " + show(m.want) }
+
+type matches struct{ re *regexp.Regexp }
+
+// Matches matches text values against a regular expression.
+func Matches(pattern string) (Matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("matches: %w", err)
+	}
+	return matches{re}, nil
+}
+
+func (m matches) Match(v interface{}, present bool) error {
+	if !present {
+		return fmt.Errorf("missing")
+	}
+	if !m.re.MatchString(text(v)) {
+		return fmt.Errorf("got %s", show(v))
+	}
+	return nil
+}
+
+func (m matches) String() string { return "matches " + m.re.String() }
+
+type compare struct {
+	op string
+	n  float64
+}
+
+func (m compare) Match(v interface{}, present bool) error {
+	if !present {
+		return fmt.Errorf("missing")
+	}
+	actual := v
+	if s, ok := v.(string); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			actual = f // e.g. Content-Length
+		}
+	}
+	if !jsonpath.Compare(actual, m.op, m.n) {
+		return fmt.Errorf("got %s", show(v))
+	}
+	return nil
+}
+
+func (m compare) String() string { return m.op + " " + show(m.n) }
+
+type length int
+
+func (m length) Match(v interface{}, present bool) error {
+	if !present {
+		return fmt.Errorf("missing")
+	}
+	n := -1
+	switch t := v.(type) {
+	case string:
+		n = len([]rune(t))
+	case []interface{}:
+		n = len(t)
+	case map[string]interface{}:
+		n = len(t)
+	}
+	if n < 0 {
+		return fmt.Errorf("got %s, which has no length", show(v))
+	}
+	if n != int(m) {
+		return fmt.Errorf("got length %d", n)
+	}
+	return nil
+}
+
+func (m length) String() string { return "has length " + strconv.Itoa(int(m)) }
+
+type typeIs string
+
+func (m typeIs) Match(v interface{}, present bool) error {
+	if !present {
+		return fmt.Errorf("missing")
+	}
+	got := typeOf(v)
+	if got == string(m) || (m == "number" && got == "integer") {
+		return nil
+	}
+	return fmt.Errorf("got %s %s", got, show(v))
+}
+
+func (m typeIs) String() string { return "is " + string(m) }
+
+func typeOf(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if t == math.Trunc(t) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// sameValue compares decoded values; a string equals a scalar with the
+// same text ("42" == 42) so headers can be compared with YAML numbers.
+func sameValue(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	switch {
+	case aok && !bok && isScalar(b):
+		return as == text(b)
+	case bok && !aok && isScalar(a):
+		return bs == text(a)
+	}
+	return false
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case float64, bool, nil:
+		return true
+	}
+	return false
+}
+
+// text renders a value as text: strings as is, others as compact JSON.
+func text(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// show renders a value for messages as compact JSON, shortened.
+func show(v interface{}) string {
+	data, err := json.Marshal(v)
+	s := string(data)
+	if err != nil {
+		s = fmt.Sprint(v)
+	}
+	if len(s) > 120 {
+		s = s[:117] + "..."
+	}
+	return s
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,296 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-rest-api-cli-demo/internal/httpclient"
+)
+
+// BatchCommand runs one request per line of a JSONL file through a worker pool.
+type BatchCommand struct {
+	Factory httpclient.Factory
+}
+
+func NewBatchCommand(factory httpclient.Factory) *BatchCommand {
+	return &BatchCommand{Factory: factory}
+}
+
+func (b *BatchCommand) Name() string        { return "batch" }
+func (b *BatchCommand) Description() string { return "Run requests from a JSONL file concurrently" }
+
+// batchSpec is one input line.
+type batchSpec struct {
+	ID      batchID           `json:"id,omitempty"`
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Profile string            `json:"profile,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// batchID is the "id" of a line: a string or a number, kept as text.
+type batchID string
+
+func (id *batchID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = batchID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("\"id\" must be a string or a number")
+	}
+	*id = batchID(n)
+	return nil
+}
+
+// batchResult is one output line.
+type batchResult struct {
+	Line       int               `json:"line"`
+	ID         string            `json:"id,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	StatusCode int               `json:"status_code,omitempty"`
+	DurationMS int64             `json:"duration_ms"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       json.RawMessage   `json:"body,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type batchJob struct {
+	line int
+	raw  []byte
+}
+
+func (b *BatchCommand) Run(args []string) error {
+	// Allow "batch FILE [flags]" as well as "batch --file FILE [flags]"
+	var positional string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+
+	var (
+		filePath     = fs.String("file", positional, "JSONL file with one request per line ('-' for stdin)")
+		outPath      = fs.String("out", "", "Write JSONL results to file (default: stdout)")
+		concurrency  = fs.Int("concurrency", 4, "Number of concurrent workers")
+		rate         = fs.Float64("rate", 0, "Maximum requests per second across all workers (0 = unlimited)")
+		profileName  = fs.String("profile", "", "Default profile for lines without \"profile\"")
+		timeoutSec   = fs.Int("timeout", 30, "Timeout in seconds (per request)")
+		insecure     = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
+		retries      = fs.Int("retries", 0, "Number of retries on failure (network/5xx)")
+		retryWait    = fs.Int("retry-delay", 1, "Delay between retries in seconds")
+		withHeaders  = fs.Bool("include-headers", false, "Include response headers in results")
+		failOnStatus = fs.Bool("fail", false, "Exit non-zero if any response is HTTP 4xx/5xx")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *filePath == "" {
+		return fmt.Errorf("a JSONL file is required (batch FILE or --file FILE)")
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if !(*rate >= 0 && *rate <= 1e9) {
+		// the ticker interval is 1s / rate and must be at least 1ns
+		return fmt.Errorf("--rate must be between 0 and 1e9 requests per second")
+	}
+
+	var in io.Reader = os.Stdin
+	if *filePath != "-" {
+		f, err := os.Open(*filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return fmt.Errorf("create results file: %w", err)
+		}
+		defer f.Close()
+		w := bufio.NewWriter(f)
+		defer w.Flush()
+		out = w
+	}
+
+	base := requestInput{
+		Profile:  *profileName,
+		Timeout:  time.Duration(*timeoutSec) * time.Second,
+		Insecure: *insecure,
+	}
+	retryDelay := time.Duration(*retryWait) * time.Second
+
+	jobs := make(chan batchJob)
+	results := make(chan batchResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				results <- b.runOne(job, base, *retries, retryDelay, *withHeaders)
+			}
+		}()
+	}
+
+	// Producer: read lines, honoring the rate limit
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		var tick <-chan time.Time
+		if *rate > 0 {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		sc := bufio.NewScanner(in)
+		sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		line := 0
+		first := true
+		for sc.Scan() {
+			line++
+			raw := bytes.TrimSpace(sc.Bytes())
+			if len(raw) == 0 {
+				continue
+			}
+			if tick != nil && !first {
+				<-tick
+			}
+			first = false
+			jobs <- batchJob{line: line, raw: append([]byte(nil), raw...)}
+		}
+		readErr <- sc.Err()
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Single writer keeps result lines intact
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	var total, failed int
+	for res := range results {
+		total++
+		if res.Error != "" || (*failOnStatus && res.StatusCode >= 400) {
+			failed++
+		}
+		if err := enc.Encode(res); err != nil {
+			return fmt.Errorf("write result: %w", err)
+		}
+	}
+
+	if err := <-readErr; err != nil {
+		return fmt.Errorf("read %s: %w", *filePath, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d request(s), %d failed\n", total, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d request(s) failed", failed, total)
+	}
+	return nil
+}
+
+func (b *BatchCommand) runOne(job batchJob, base requestInput, retries int, retryDelay time.Duration, withHeaders bool) batchResult {
+	res := batchResult{Line: job.line}
+
+	var spec batchSpec
+	if err := json.Unmarshal(job.raw, &spec); err != nil {
+		res.Error = fmt.Sprintf("invalid request spec: %v", err)
+		return res
+	}
+	res.ID = string(spec.ID)
+
+	if spec.URL == "" {
+		res.Error = "\"url\" is required"
+		return res
+	}
+	if spec.Method == "" {
+		spec.Method = "GET"
+	}
+
+	in := base
+	in.Method = spec.Method
+	in.URL = spec.URL
+	in.Headers = spec.Headers
+	if spec.Profile != "" {
+		in.Profile = spec.Profile
+	}
+	if len(spec.Body) > 0 && string(spec.Body) != "null" {
+		in.Body = spec.Body
+	}
+
+	resolved, err := resolveRequest(in)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Method = resolved.Config.Method
+	res.URL = resolved.Config.URL
+
+	start := time.Now()
+	resp, err := sendWithRetry(b.Factory, resolved.Config, retries, retryDelay)
+	var failed *statusError
+	if errors.As(err, &failed) {
+		// Retries are used up: still an error, but keep status and body
+		res.Error = err.Error()
+		resp, err = failed.resp, nil
+	}
+	if err != nil {
+		res.DurationMS = time.Since(start).Milliseconds()
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	res.DurationMS = time.Since(start).Milliseconds()
+	res.Status = resp.Status
+	res.StatusCode = resp.StatusCode
+	if err != nil {
+		res.Error = fmt.Sprintf("read response: %v", err)
+		return res
+	}
+
+	if withHeaders {
+		res.Headers = make(map[string]string, len(resp.Header))
+		for k, v := range resp.Header {
+			res.Headers[k] = strings.Join(v, ", ")
+		}
+	}
+
+	// JSON bodies are embedded as-is, anything else as a JSON string
+	if json.Valid(body) && len(bytes.TrimSpace(body)) > 0 {
+		res.Body = body
+	} else if len(body) > 0 {
+		res.Body, _ = json.Marshal(string(body))
+	}
+	return res
+}
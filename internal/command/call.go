@@ -3,18 +3,21 @@ package command
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
-	"go-rest-api-cli-demo/internal/auth"
-	cfgstore "go-rest-api-cli-demo/internal/config"
+	"go-rest-api-cli-demo/internal/assert"
+	"go-rest-api-cli-demo/internal/filter"
 	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/output"
+	"go-rest-api-cli-demo/internal/paginate"
 	"go-rest-api-cli-demo/internal/payload"
+	"go-rest-api-cli-demo/internal/vars"
 )
 
 // CallCommand = "call" subcommand.
@@ -38,6 +41,8 @@ func (c *CallCommand) Run(args []string) error {
 		profileName  = fs.String("profile", "", "Profile name to use from config")
 		inlineJSON   = fs.String("data", "", "Inline JSON body")
 		jsonFilePath = fs.String("json-file", "", "Path to JSON file with extra payload")
+		mergeKind    = fs.String("merge", "shallow", "How --data is merged into --json-file: "+payload.MergeKinds)
+		mergeArrays  = fs.String("merge-arrays", "replace", "deep/shallow: what happens when two arrays meet: "+payload.ArrayModes)
 		timeoutSec   = fs.Int("timeout", 30, "Timeout in seconds")
 		insecure     = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
 
@@ -52,139 +57,355 @@ func (c *CallCommand) Run(args []string) error {
 		outPath   = fs.String("out", "", "Write response body to file")
 		retries   = fs.Int("retries", 0, "Number of retries on failure (network/5xx)")
 		retryWait = fs.Int("retry-delay", 1, "Delay between retries in seconds")
+		dryRun    = fs.Bool("dry-run", false, "Resolve and print the request (with value sources) without sending it")
+		varsFile  = fs.String("vars-file", vars.DefaultFile, "Variables file for {{name}} placeholders and --capture")
+		outFormat = fs.String("output", "text", "Output format: "+output.Formats)
+		filterStr = fs.String("filter", "", "JSONPath ($.a[*].b) or jq-style (.a[].b) expression applied to the JSON response")
 	)
 
+	var (
+		paginateKind  = fs.String("paginate", "", "Follow pages: "+paginate.Kinds)
+		itemsPath     = fs.String("items-path", "", "JSON path of the items array on each page (default: the body itself)")
+		maxPages      = fs.Int("max-pages", 100, "Maximum number of pages to fetch")
+		ndjson        = fs.Bool("ndjson", false, "With --paginate, stream items as NDJSON instead of one array")
+		cursorPath    = fs.String("cursor-path", "", "cursor: JSON path of the next cursor in the body")
+		cursorParam   = fs.String("cursor-param", "cursor", "cursor: query parameter that receives the cursor")
+		pageParam     = fs.String("page-param", "", "page/offset: query parameter (default page or offset)")
+		pageStart     = fs.Int("page-start", 1, "page: number of the first page")
+		pageSize      = fs.Int("page-size", 0, "page/offset: items per page (also ends on a short page)")
+		pageSizeParam = fs.String("page-size-param", "limit", "page/offset: query parameter for --page-size")
+	)
+
+	var (
+		sseMode       = fs.Bool("sse", false, "Request a text/event-stream and print events as they arrive")
+		maxEvents     = fs.Int("max-events", 0, "Stop streaming after N events (0 = no limit)")
+		untilEvent    = fs.String("until-event", "", "Stop streaming after an event of this type")
+		streamTimeout = fs.Int("stream-timeout", 0, "Stop streaming after N seconds (0 = no limit)")
+		reconnects    = fs.Int("reconnect", 0, "Reconnect with Last-Event-ID up to N times when the stream ends")
+		streamJSON    = fs.Bool("stream", false, "Stream JSON responses value by value (NDJSON, or the elements of a top-level array)")
+	)
+
+	expandJSONFile := fs.Bool("expand-json-file", false, "Expand {{name}} placeholders and template functions in the --json-file content")
+	showSecrets := fs.Bool("show-secrets", false, "With --dry-run, print credential headers (Authorization, Cookie, API keys) in clear text")
+
 	headers := HeaderFlag{} // initialized non-nil
 	fs.Var(&headers, "header", "HTTP header 'Key: Value' (can be repeated)")
-
-	if err := fs.Parse(args); err != nil {
+	cliVars := VarFlag{}
+	fs.Var(&cliVars, "var", "Variable 'name=value' for {{name}} placeholders (can be repeated)")
+	formFields := ListFlag{}
+	fs.Var(&formFields, "form", "Multipart form field 'name=value' (can be repeated)")
+	formFiles := ListFlag{}
+	fs.Var(&formFiles, "file", "Multipart file 'name=@path[;type=mime][;filename=name]' (can be repeated)")
+	urlencoded := ListFlag{}
+	fs.Var(&urlencoded, "form-urlencoded", "URL-encoded form field 'name=value' (can be repeated)")
+	bodyFile := fs.String("body-file", "", "Send the raw bytes of FILE as the body, any content type ('-' = stdin)")
+	progress := fs.Bool("progress", false, "Always show upload progress (default: bodies over 1 MB on a terminal)")
+	requestSchema := fs.String("request-schema", "", "JSON Schema (draft 2020-12) the JSON request body must match; checked before sending")
+	responseSchema := fs.String("response-schema", "", "JSON Schema (draft 2020-12) the JSON response body must match")
+	strict := fs.Bool("strict", false, "Fail (exit 5) instead of warning when the call violates the profile's OpenAPI document")
+	noContract := fs.Bool("no-contract", false, "Skip the checks against the profile's OpenAPI document")
+	expectStatus := fs.String("expect-status", "", "Expected status: 200, 2xx, 201,204 or 200-299 (exit 6 if not; 4xx/5xx are then not errors)")
+	expectHeaders := ListFlag{}
+	fs.Var(&expectHeaders, "expect-header", "Expected header: 'Name', 'Name: value' or 'Name: /regex/' (can be repeated)")
+	expectJSON := ListFlag{}
+	fs.Var(&expectJSON, "expect-json", "Expected JSON value: '$.path', '$.path=value' or '$.path=~regex' (can be repeated)")
+	snapshotPath := fs.String("snapshot", "", "Compare the response with the snapshot FILE (written on the first run; exit 6 if it differs)")
+	snapshotIgnore := ListFlag{}
+	fs.Var(&snapshotIgnore, "snapshot-ignore", "JSON path in the body left out of the snapshot, e.g. $.id or $..createdAt (can be repeated)")
+	snapshotIgnoreHeaders := ListFlag{}
+	fs.Var(&snapshotIgnoreHeaders, "snapshot-ignore-header", "Response header left out of the snapshot (can be repeated)")
+	updateSnapshot := fs.Bool("update-snapshot", false, "Overwrite the --snapshot file with this response")
+	captures := CaptureFlag{}
+	fs.Var(&captures, "capture", "Store a response value: name=<$.json.path|header:Name|regex:pattern> (can be repeated)")
+
+	// Flags may be mixed with request items: name=value, name:=json,
+	// name==query, Header:Value
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
 		return err
 	}
+	items, err := parseRequestItems(positional)
+	if err != nil {
+		return err
+	}
+	bodyItems := false
+	for _, it := range items {
+		bodyItems = bodyItems || it.isBody()
+	}
 
 	if *urlStr == "" {
 		return fmt.Errorf("--url is required")
 	}
 
-	// Load profiles if requested
-	var (
-		baseURLFromProfile string
-		profileHeaders     map[string]string
-		profileAuthType    string
-		profileUser        string
-		profilePass        string
-		profileToken       string
-	)
-	if *profileName != "" {
-		cfg, err := cfgstore.Load()
+	merger, err := payload.NewMerger(*mergeKind, *mergeArrays)
+	if err != nil {
+		return err
+	}
+
+	renderer, err := output.New(*outFormat, output.Options{Pretty: *pretty, Raw: *raw, JSONOnly: *jsonOnly})
+	if err != nil {
+		return err
+	}
+	textMode := strings.ToLower(*outFormat) == "text"
+
+	var respFilter *filter.Filter
+	if *filterStr != "" {
+		f, err := filter.Compile(*filterStr)
+		if err != nil {
+			return fmt.Errorf("--filter: %w", err)
+		}
+		respFilter = f
+	}
+
+	var pages *pageOptions
+	if *paginateKind != "" {
+		strategy, err := paginate.New(*paginateKind, paginate.Options{
+			CursorPath:    *cursorPath,
+			CursorParam:   *cursorParam,
+			PageParam:     *pageParam,
+			PageStart:     *pageStart,
+			PageSize:      *pageSize,
+			PageSizeParam: *pageSizeParam,
+		})
 		if err != nil {
-			return fmt.Errorf("load config: %w", err)
+			return err
 		}
-		p, ok := cfg.Profiles[*profileName]
-		if !ok {
-			return fmt.Errorf("profile %q not found", *profileName)
+		if *maxPages < 1 {
+			return fmt.Errorf("--max-pages must be at least 1")
+		}
+		pages = &pageOptions{strategy: strategy, itemsPath: *itemsPath, maxPages: *maxPages}
+	} else if *ndjson {
+		return fmt.Errorf("--ndjson requires --paginate")
+	}
+
+	if (*sseMode || *streamJSON) && pages != nil {
+		return fmt.Errorf("--sse and --stream cannot be combined with --paginate")
+	}
+	if *sseMode && !textMode && strings.ToLower(*outFormat) != "json" {
+		return fmt.Errorf("--sse supports --output text or json")
+	}
+	if *responseSchema != "" && (*sseMode || *streamJSON || pages != nil) {
+		return fmt.Errorf("--response-schema checks a single response; it cannot be combined with --sse, --stream or --paginate")
+	}
+	expectations, err := buildExpectations(*expectStatus, expectHeaders, expectJSON)
+	if err != nil {
+		return err
+	}
+	if (len(expectHeaders) > 0 || len(expectJSON) > 0) && (*sseMode || *streamJSON) {
+		return fmt.Errorf("--expect-header and --expect-json check a single response; they cannot be combined with --sse or --stream")
+	}
+	snapOpts, err := newSnapshotOptions(snapshotIgnore, snapshotIgnoreHeaders, *updateSnapshot)
+	if err != nil {
+		return err
+	}
+	if *snapshotPath == "" && (len(snapshotIgnore) > 0 || len(snapshotIgnoreHeaders) > 0 || *updateSnapshot) {
+		return fmt.Errorf("--snapshot-ignore, --snapshot-ignore-header and --update-snapshot need --snapshot FILE")
+	}
+	if *snapshotPath != "" && (*sseMode || *streamJSON) {
+		return fmt.Errorf("--snapshot records a single response; it cannot be combined with --sse or --stream")
+	}
+	reqSchema, err := loadSchema("request-schema", *requestSchema)
+	if err != nil {
+		return err
+	}
+	respSchema, err := loadSchema("response-schema", *responseSchema)
+	if err != nil {
+		return err
+	}
+	var spec *contract
+	if !*noContract {
+		if spec, err = loadContract(*profileName, *strict); err != nil {
+			return err
 		}
-		baseURLFromProfile = p.BaseURL
-		profileHeaders = p.Headers
-		profileAuthType = strings.ToLower(p.AuthType)
-		profileUser = p.User
-		profilePass = p.Pass
-		profileToken = p.Token
 	}
 
-	// Effective URL (profile base URL + relative path)
-	finalURL := *urlStr
-	if baseURLFromProfile != "" &&
-		!strings.HasPrefix(strings.ToLower(*urlStr), "http://") &&
-		!strings.HasPrefix(strings.ToLower(*urlStr), "https://") {
-		finalURL = strings.TrimRight(baseURLFromProfile, "/") + "/" + strings.TrimLeft(*urlStr, "/")
+	// Variables: vars file, overridden by --var
+	variables, err := vars.LoadFile(*varsFile)
+	if err != nil {
+		return fmt.Errorf("load variables: %w", err)
+	}
+	for k, v := range cliVars {
+		variables[k] = v
 	}
 
-	// JSON: load file + inline, merge
-	fileMap := map[string]interface{}{}
-	inlineMap := map[string]interface{}{}
-	var err error
+	// Expand {{name}} placeholders in URL, headers and body
+	*urlStr, err = vars.Expand(*urlStr, variables)
+	if err != nil {
+		return fmt.Errorf("--url: %w", err)
+	}
+	for k, v := range headers {
+		if headers[k], err = vars.Expand(v, variables); err != nil {
+			return fmt.Errorf("header %s: %w", k, err)
+		}
+	}
+	if *urlStr, err = applyURLItems(items, *urlStr, headers, variables); err != nil {
+		return err
+	}
+
+	// JSON: load file + inline, merge. Bodies may be objects, arrays or
+	// scalars; nil means "not given".
+	var fileBody, inlineBody interface{}
 
 	if *jsonFilePath != "" {
-		fileMap, err = payload.LoadJSONFile(*jsonFilePath)
+		data, err := os.ReadFile(*jsonFilePath)
+		if err != nil {
+			return fmt.Errorf("loading json-file: %w", err)
+		}
+		// The file is sent as written unless placeholders are asked for
+		content := string(data)
+		if *expandJSONFile {
+			if content, err = vars.Expand(content, variables); err != nil {
+				return fmt.Errorf("json-file: %w", err)
+			}
+		}
+		fileBody, err = payload.ParseJSONInline(content)
 		if err != nil {
 			return fmt.Errorf("loading json-file: %w", err)
 		}
 	}
 
+	// --data may also be @file or @- (stdin); such content is taken
+	// byte for byte (no placeholders) and sent as is when it is not JSON
+	var rawBody []byte
 	if *inlineJSON != "" {
-		inlineMap, err = payload.ParseJSONInline(*inlineJSON)
+		data, fromSource, err := payload.ReadArg(*inlineJSON, os.Stdin)
 		if err != nil {
-			return fmt.Errorf("parsing inline JSON: %w", err)
+			return fmt.Errorf("--data: %w", err)
+		}
+		expanded := data
+		if !fromSource {
+			if expanded, err = vars.Expand(data, variables); err != nil {
+				return fmt.Errorf("--data: %w", err)
+			}
+		}
+		inlineBody, err = payload.ParseJSONInline(expanded)
+		if err != nil {
+			if !fromSource {
+				return fmt.Errorf("parsing inline JSON: %w", err)
+			}
+			if *jsonFilePath != "" || bodyItems {
+				return fmt.Errorf("--data %s is not JSON and cannot be merged with --json-file or request items", *inlineJSON)
+			}
+			rawBody = []byte(expanded)
 		}
 	}
 
-	// Merge headers: profile headers first, then CLI overrides
-	effectiveHeaders := HeaderFlag{}
-	if profileHeaders != nil {
-		for k, v := range profileHeaders {
-			effectiveHeaders[k] = v
+	// Only one kind of body per request
+	kinds := 0
+	for _, used := range []bool{
+		*inlineJSON != "" || *jsonFilePath != "" || bodyItems,
+		len(urlencoded) > 0,
+		len(formFields) > 0 || len(formFiles) > 0,
+		*bodyFile != "",
+	} {
+		if used {
+			kinds++
 		}
 	}
-	for k, v := range headers {
-		effectiveHeaders[k] = v
+	if kinds > 1 {
+		return fmt.Errorf("use only one body source: --data/--json-file/request items, --form-urlencoded, --form/--file or --body-file")
 	}
 
-	var body []byte
-	if len(fileMap) > 0 || len(inlineMap) > 0 {
-		merged := payload.Merge(fileMap, inlineMap)
+	// Multipart upload (streamed, never held in memory)
+	upload, err := buildUpload(formFields, formFiles, variables)
+	if err != nil {
+		return err
+	}
+	if (upload != nil || len(urlencoded) > 0 || *bodyFile != "" || bodyItems) && !flagPassed(fs, "method") {
+		*method = "POST" // forms, files and body items are posted unless --method says otherwise
+	}
+
+	var (
+		body     []byte
+		bodyType string // Content-Type of non-JSON bodies
+	)
+	switch {
+	case rawBody != nil:
+		body, bodyType = rawBody, sniffContentType(rawBody)
+	case len(urlencoded) > 0:
+		pairs := make([]string, len(urlencoded))
+		for i, p := range urlencoded {
+			if pairs[i], err = vars.Expand(p, variables); err != nil {
+				return fmt.Errorf("--form-urlencoded: %w", err)
+			}
+		}
+		encoded, err := payload.EncodeForm(pairs)
+		if err != nil {
+			return err
+		}
+		body, bodyType = []byte(encoded), "application/x-www-form-urlencoded"
+	case fileBody != nil || inlineBody != nil || bodyItems:
+		merged := inlineBody
+		switch {
+		case fileBody != nil && inlineBody != nil:
+			merged = merger.Merge(fileBody, inlineBody)
+		case fileBody != nil:
+			merged = fileBody
+		}
+		// Request items are applied last, so they win over --data/--json-file
+		if bodyItems {
+			if merged == nil {
+				merged = map[string]interface{}{}
+			}
+			obj, ok := merged.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("request items need a JSON object body, but --data/--json-file give %s", jsonKind(merged))
+			}
+			if err := applyBodyItems(items, obj, variables); err != nil {
+				return err
+			}
+		}
 		body, err = json.Marshal(merged)
 		if err != nil {
 			return fmt.Errorf("marshalling merged JSON: %w", err)
 		}
-
-		// Ensure Content-Type if not set
-		if _, ok := effectiveHeaders["Content-Type"]; !ok {
-			effectiveHeaders["Content-Type"] = "application/json"
-		}
 	}
 
-	// Choose auth strategy (profile defaults + CLI overrides)
-	finalAuthType := strings.ToLower(*authType)
-	finalUser := *user
-	finalPass := *pass
-	finalToken := *token
-
-	// Use profile defaults if CLI didn't override
-	if *profileName != "" {
-		if (finalAuthType == "" || finalAuthType == "none") && profileAuthType != "" && profileAuthType != "none" {
-			finalAuthType = profileAuthType
-		}
-		if finalUser == "" && profileUser != "" {
-			finalUser = profileUser
-		}
-		if finalPass == "" && profilePass != "" {
-			finalPass = profilePass
+	// Contract check of the outgoing JSON body (also with --dry-run)
+	if reqSchema != nil {
+		if len(body) == 0 || (bodyType != "" && !strings.Contains(bodyType, "json")) {
+			return fmt.Errorf("--request-schema needs a JSON request body (--data, --json-file or request items)")
 		}
-		if finalToken == "" && profileToken != "" {
-			finalToken = profileToken
+		if err := checkSchema(reqSchema, *requestSchema, "request body", body); err != nil {
+			return err
 		}
 	}
 
-	var authStrategy auth.Strategy = auth.NoAuth{}
-	switch finalAuthType {
-	case "basic":
-		authStrategy = auth.Basic{User: finalUser, Pass: finalPass}
-	case "bearer":
-		authStrategy = auth.Bearer{Token: finalToken}
-	case "", "none":
-		// default no auth
-	default:
-		return fmt.Errorf("unknown auth type: %s", finalAuthType)
+	// Profile defaults + CLI overrides for URL, headers and auth
+	resolved, err := resolveRequest(requestInput{
+		Method:   *method,
+		URL:      *urlStr,
+		Profile:  *profileName,
+		Headers:  headers,
+		Body:     body,
+		BodyType: bodyType,
+		AuthType: *authType,
+		User:     *user,
+		Pass:     *pass,
+		Token:    *token,
+		Timeout:  time.Duration(*timeoutSec) * time.Second,
+		Insecure: *insecure,
+	})
+	if err != nil {
+		return err
 	}
-
-	cfg := httpclient.Config{
-		Method:        strings.ToUpper(*method),
-		URL:           finalURL,
-		Headers:       effectiveHeaders,
-		Body:          body,
-		Timeout:       time.Duration(*timeoutSec) * time.Second,
-		Auth:          authStrategy,
-		SkipTLSVerify: *insecure,
+	if upload != nil {
+		applyUpload(resolved, upload, *progress)
+	}
+	if *bodyFile != "" {
+		if err := applyBodyFile(resolved, *bodyFile, *progress); err != nil {
+			return err
+		}
+	}
+	cfg := resolved.Config
+	if *sseMode || *streamJSON {
+		// Streams are open-ended or large: --timeout would cut them off
+		cfg.Timeout = 0
+	}
+	if *sseMode {
+		if _, ok := cfg.Headers["Accept"]; !ok {
+			cfg.Headers["Accept"] = "text/event-stream"
+		}
 	}
 
 	// Print request preview (once)
@@ -193,93 +414,173 @@ func (c *CallCommand) Run(args []string) error {
 		return fmt.Errorf("build request preview: %w", err)
 	}
 
-	fmt.Println("=== Request ===")
-	fmt.Printf("%s %s\n", reqPreview.Method, reqPreview.URL.String())
-	for k, v := range reqPreview.Header {
-		fmt.Printf("%s: %s\n", k, strings.Join(v, ", "))
+	// Contract check against the profile's OpenAPI document (also with
+	// --dry-run); streamed bodies only have their content type checked
+	if spec != nil {
+		if err := spec.checkRequest(reqPreview, body); err != nil {
+			return err
+		}
 	}
-	if len(body) > 0 {
-		fmt.Println()
-		fmt.Println("Body:")
-		fmt.Println(string(body))
+
+	if *dryRun {
+		printDryRun(resolved, reqPreview, *showSecrets)
+		return nil
 	}
 
-	// Retry logic
-	attempts := *retries + 1
-	if attempts < 1 {
-		attempts = 1
+	// Structured formats keep stdout machine-readable
+	if textMode {
+		fmt.Println("=== Request ===")
+		fmt.Printf("%s %s\n", reqPreview.Method, reqPreview.URL.String())
+		for k, v := range reqPreview.Header {
+			fmt.Printf("%s: %s\n", k, strings.Join(v, ", "))
+		}
+		if len(body) > 0 {
+			fmt.Println()
+			fmt.Println("Body:")
+			fmt.Println(bodyPreview(body))
+		} else if resolved.BodySummary != "" {
+			fmt.Println()
+			fmt.Println("Body:")
+			fmt.Println(resolved.BodySummary)
+		}
 	}
 
+	// Send (with retries), or follow pages
+	start := time.Now()
+	retryDelay := time.Duration(*retryWait) * time.Second
 	var (
-		resp    *http.Response
-		lastErr error
+		resp     *http.Response
+		respBody []byte
 	)
-
-	for i := 0; i < attempts; i++ {
-		req, client, err := c.Factory.Build(cfg)
+	if pages != nil {
+		if *ndjson {
+			w, closeOut, err := streamWriter(*outPath)
+			if err != nil {
+				return err
+			}
+			defer closeOut()
+			pages.emit = func(item interface{}) error {
+				data, err := json.Marshal(item)
+				if err != nil {
+					return err
+				}
+				return writeJSONItem(w, data, respFilter, false, *raw)
+			}
+		}
+		resp, respBody, err = c.fetchPages(cfg, *pages, *retries, retryDelay)
 		if err != nil {
-			return fmt.Errorf("build request: %w", err)
+			return err
+		}
+		if pages.emit != nil && resp.StatusCode < 400 {
+			// The items are printed already; expectations check the last page
+			if len(expectations) > 0 {
+				return checkExpectations(expectations, &assert.Response{
+					Status:   resp.StatusCode,
+					Header:   resp.Header,
+					Body:     respBody,
+					Duration: time.Since(start),
+				})
+			}
+			return nil
+		}
+	} else {
+		// --timeout bounds the response headers and then the body read
+		// below, so auto-detected streams are not cut off
+		bodyTimeout := cfg.Timeout
+		cfg.HeaderTimeout, cfg.Timeout = cfg.Timeout, 0
+		resp, err = sendWithRetry(c.Factory, cfg, *retries, retryDelay)
+		var failed *statusError
+		if errors.As(err, &failed) {
+			// Retries are used up: show the 5xx like any other response,
+			// the status checks below decide the exit code
+			resp, err = failed.resp, nil
 		}
-
-		resp, err = client.Do(req)
 		if err != nil {
-			lastErr = err
-		} else if resp.StatusCode >= 500 && resp.StatusCode <= 599 {
-			lastErr = fmt.Errorf("received HTTP %d", resp.StatusCode)
-		} else {
-			lastErr = nil
-			break
+			return &ExitError{Code: exitTransport, Err: err}
 		}
-
-		if resp != nil {
-			io.Copy(io.Discard, resp.Body)
-			resp.Body.Close()
+		defer resp.Body.Close()
+
+		// Only answers that pass the status check are streamed; an error
+		// status (or one --expect-status rejects) is printed whole below
+		// and sets the exit code like any other response
+		stream := isEventStream(resp) || isJSONStream(resp, *streamJSON)
+		if stream && *expectStatus == "" {
+			stream = resp.StatusCode < 400
+		} else if stream {
+			want, _ := assert.Status(*expectStatus) // checked by buildExpectations
+			stream = want.Check(&assert.Response{Status: resp.StatusCode}) == nil
 		}
-
-		if i < attempts-1 {
-			time.Sleep(time.Duration(*retryWait) * time.Second)
+		if stream && len(captures) > 0 {
+			fmt.Fprintln(os.Stderr, "Warning: --capture is ignored for streamed responses")
+		}
+		if stream && respSchema != nil {
+			fmt.Fprintln(os.Stderr, "Warning: --response-schema is ignored for streamed responses")
+		}
+		if stream && (len(expectHeaders) > 0 || len(expectJSON) > 0) {
+			fmt.Fprintln(os.Stderr, "Warning: --expect-header and --expect-json are ignored for streamed responses")
+		}
+		if stream && *snapshotPath != "" {
+			fmt.Fprintln(os.Stderr, "Warning: --snapshot is ignored for streamed responses")
+		}
+		if stream && isJSONStream(resp, *streamJSON) {
+			return handleJSONStream(resp, *outPath, textMode, jsonStreamOptions{
+				splitArray: *streamJSON,
+				jsonLines:  !textMode,
+				raw:        *raw || *jsonOnly,
+				pretty:     *pretty,
+				filter:     respFilter,
+			})
+		}
+		if stream {
+			return c.handleEventStream(cfg, resp, *outPath, textMode, sseOptions{
+				maxEvents:  *maxEvents,
+				untilEvent: *untilEvent,
+				duration:   time.Duration(*streamTimeout) * time.Second,
+				reconnects: *reconnects,
+				jsonLines:  !textMode,
+				raw:        *raw || *jsonOnly,
+				pretty:     *pretty,
+				filter:     respFilter,
+			}, *retries, retryDelay)
 		}
-	}
-
-	if lastErr != nil {
-		return fmt.Errorf("request failed after %d attempt(s): %w", attempts, lastErr)
-	}
-
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("read response: %w", err)
+		respBody, err = readWithTimeout(resp.Body, bodyTimeout)
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
 	}
+	elapsed := time.Since(start)
 
 	// Decide how to print based on flags
 	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
 	isJSON := strings.HasPrefix(contentType, "application/json")
 
 	bodyToPrint := respBody
-	if isJSON && *pretty {
+	if respFilter != nil {
+		// json-only keeps JSON output; raw alone prints strings unquoted
+		bodyToPrint, err = applyFilter(respFilter, respBody, *pretty, *raw && !*jsonOnly)
+		if err != nil {
+			return err
+		}
+	} else if isJSON && *pretty {
 		var buf bytes.Buffer
 		if err := json.Indent(&buf, respBody, "", "  "); err == nil {
 			bodyToPrint = buf.Bytes()
 		}
 	}
 
-	// json-only overrides raw if both set
-	if *jsonOnly {
-		// Only print body (pretty if requested)
-		fmt.Println(string(bodyToPrint))
-	} else if *raw {
-		// Raw body only
-		fmt.Println(string(bodyToPrint))
-	} else {
-		// Default: status + headers + body
-		fmt.Println("\n=== Response ===")
-		fmt.Printf("Status: %s\n", resp.Status)
-		for k, v := range resp.Header {
-			fmt.Printf("%s: %s\n", k, strings.Join(v, ", "))
-		}
-		fmt.Println()
-		fmt.Println(string(bodyToPrint))
+	if err := renderer.Render(os.Stdout, &output.Result{
+		Method:         reqPreview.Method,
+		URL:            reqPreview.URL.String(),
+		RequestHeaders: reqPreview.Header,
+		RequestBody:    body,
+		Status:         resp.Status,
+		StatusCode:     resp.StatusCode,
+		Headers:        resp.Header,
+		Duration:       elapsed,
+		Body:           bodyToPrint,
+	}); err != nil {
+		return fmt.Errorf("render output: %w", err)
 	}
 
 	// Save to file if requested
@@ -289,5 +590,41 @@ func (c *CallCommand) Run(args []string) error {
 		}
 	}
 
+	// Store captured values for later calls
+	if len(captures) > 0 {
+		if err := saveCaptures(*varsFile, captures, resp.Header, respBody); err != nil {
+			return err
+		}
+	}
+
+	// Every check runs and reports; the exit code is the first that
+	// applies: HTTP status (4), schema/contract (5), expectations and
+	// snapshot (6)
+	var errs []error
+	if *expectStatus == "" && resp.StatusCode >= 400 {
+		errs = append(errs, &ExitError{Code: exitHTTPStatus, Err: fmt.Errorf("HTTP %s", resp.Status)})
+	}
+	if respSchema != nil {
+		errs = append(errs, checkSchema(respSchema, *responseSchema, "response body", respBody))
+	}
+	if spec != nil && pages == nil {
+		errs = append(errs, spec.checkResponse(resp, respBody))
+	}
+	if len(expectations) > 0 {
+		errs = append(errs, checkExpectations(expectations, &assert.Response{
+			Status:   resp.StatusCode,
+			Header:   resp.Header,
+			Body:     respBody,
+			Duration: elapsed,
+		}))
+	}
+	if *snapshotPath != "" {
+		errs = append(errs, checkSnapshot(*snapshotPath, snapOpts, resp.StatusCode, resp.Header, respBody))
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/jsonpath"
+	"go-rest-api-cli-demo/internal/vars"
+)
+
+// captureSpec is one --capture name=<source>.
+type captureSpec struct {
+	Name   string
+	Kind   string // json|header|regex
+	Expr   string
+	regexp *regexp.Regexp
+}
+
+// CaptureFlag implements flag.Value for repeated --capture flags:
+//
+//	id=$.data.id           JSON path into the response body
+//	loc=header:Location    response header
+//	csrf=regex:token=(\w+) first submatch (or whole match) in the body
+type CaptureFlag []captureSpec
+
+func (c *CaptureFlag) String() string {
+	parts := make([]string, 0, len(*c))
+	for _, s := range *c {
+		parts = append(parts, fmt.Sprintf("%s=%s:%s", s.Name, s.Kind, s.Expr))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c *CaptureFlag) Set(value string) error {
+	name, src, ok := strings.Cut(value, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || src == "" {
+		return fmt.Errorf("invalid capture, expected 'name=<json path|header:Name|regex:pattern>'")
+	}
+
+	spec := captureSpec{Name: name}
+	switch {
+	case strings.HasPrefix(src, "header:"):
+		spec.Kind, spec.Expr = "header", strings.TrimSpace(strings.TrimPrefix(src, "header:"))
+	case strings.HasPrefix(src, "regex:"):
+		spec.Kind, spec.Expr = "regex", strings.TrimPrefix(src, "regex:")
+		re, err := regexp.Compile(spec.Expr)
+		if err != nil {
+			return fmt.Errorf("capture %s: %w", name, err)
+		}
+		spec.regexp = re
+	default:
+		spec.Kind, spec.Expr = "json", strings.TrimPrefix(src, "json:")
+	}
+	*c = append(*c, spec)
+	return nil
+}
+
+// extract returns the captured value from a response.
+func (s captureSpec) extract(header http.Header, body []byte) (string, error) {
+	switch s.Kind {
+	case "header":
+		if _, ok := header[http.CanonicalHeaderKey(s.Expr)]; !ok {
+			return "", fmt.Errorf("header %q not in response", s.Expr)
+		}
+		return header.Get(s.Expr), nil
+	case "regex":
+		m := s.regexp.FindSubmatch(body)
+		if m == nil {
+			return "", fmt.Errorf("regex %q did not match the response body", s.Expr)
+		}
+		if len(m) > 1 {
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+	default:
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return "", fmt.Errorf("response body is not JSON: %w", err)
+		}
+		v, err := jsonpath.Get(doc, s.Expr)
+		if err != nil {
+			return "", err
+		}
+		return scalarString(v), nil
+	}
+}
+
+// saveCaptures extracts every capture from the response and merges the
+// values into the variables file. Nothing is written if any capture fails.
+func saveCaptures(path string, captures CaptureFlag, header http.Header, body []byte) error {
+	stored, err := vars.LoadFile(path)
+	if err != nil {
+		return fmt.Errorf("load variables: %w", err)
+	}
+	for _, c := range captures {
+		v, err := c.extract(header, body)
+		if err != nil {
+			return fmt.Errorf("capture %s: %w", c.Name, err)
+		}
+		stored[c.Name] = v
+		fmt.Fprintf(os.Stderr, "Captured %s = %s\n", c.Name, v)
+	}
+	if err := vars.SaveFile(path, stored); err != nil {
+		return fmt.Errorf("save variables: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	cfgstore "go-rest-api-cli-demo/internal/config"
+	"go-rest-api-cli-demo/internal/openapi"
+)
+
+// contract checks a call against the OpenAPI document linked to its
+// profile. Violations are warnings, or errors with --strict.
+type contract struct {
+	doc    *openapi.Document
+	base   string // path of the profile's base URL
+	strict bool
+
+	op         *openapi.Operation
+	pathValues map[string]string
+}
+
+// loadContract returns the contract of a profile, or nil when the profile
+// has no OpenAPI document.
+func loadContract(profileName string, strict bool) (*contract, error) {
+	if profileName == "" {
+		return nil, nil
+	}
+	cfg, err := cfgstore.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	pf := cfg.Profiles[profileName]
+	if pf.OpenAPI == "" {
+		return nil, nil
+	}
+	doc, err := openapi.Load(pf.OpenAPI)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", profileName, err)
+	}
+	c := &contract{doc: doc, strict: strict}
+	if u, err := url.Parse(pf.BaseURL); err == nil {
+		c.base = u.Path
+	}
+	return c, nil
+}
+
+// checkRequest matches the request to an operation and checks it. body is
+// nil for streamed bodies.
+func (c *contract) checkRequest(req *http.Request, body []byte) error {
+	c.op, c.pathValues = c.doc.Match(req.Method, req.URL.EscapedPath(), c.base)
+	if c.op == nil {
+		return c.report("request", []openapi.Violation{{
+			Where:   "operation",
+			Message: fmt.Sprintf("no operation in %s matches %s %s", c.doc.Path, req.Method, req.URL.EscapedPath()),
+		}})
+	}
+	vs, err := c.doc.CheckRequest(c.op, c.pathValues, req, body)
+	if err != nil {
+		return fmt.Errorf("openapi: %w", err)
+	}
+	return c.report("request", vs)
+}
+
+// checkResponse checks the response of a matched operation.
+func (c *contract) checkResponse(resp *http.Response, body []byte) error {
+	if c.op == nil {
+		return nil
+	}
+	vs, err := c.doc.CheckResponse(c.op, resp.StatusCode, resp.Header, body)
+	if err != nil {
+		return fmt.Errorf("openapi: %w", err)
+	}
+	return c.report("response", vs)
+}
+
+// report prints violations to stderr; with --strict they fail the call
+// with exitSchemaInvalid.
+func (c *contract) report(what string, vs []openapi.Violation) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	label := "Warning"
+	if c.strict {
+		label = "Error"
+	}
+	target := "the OpenAPI contract"
+	if c.op != nil {
+		target += " (" + c.op.ID + ")"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s does not match %s:\n", label, what, target)
+	for _, v := range vs {
+		fmt.Fprintf(os.Stderr, "  %s\n", v)
+	}
+	if c.strict {
+		return &ExitError{Code: exitSchemaInvalid, Err: fmt.Errorf("%s violates the OpenAPI contract (%d violation(s))", what, len(vs))}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/jsondiff"
+	"go-rest-api-cli-demo/internal/payload"
+	"go-rest-api-cli-demo/internal/vars"
+)
+
+// DiffCommand = "diff" subcommand: sends one request to two profiles or
+// base URLs and compares the responses.
+type DiffCommand struct {
+	Factory httpclient.Factory
+}
+
+func NewDiffCommand(factory httpclient.Factory) *DiffCommand {
+	return &DiffCommand{Factory: factory}
+}
+
+func (d *DiffCommand) Name() string { return "diff" }
+func (d *DiffCommand) Description() string {
+	return "Send a request to two profiles or base URLs and diff the responses"
+}
+
+// diffSide is one of the two targets and what it answered.
+type diffSide struct {
+	name     string // "left" or "right"
+	config   httpclient.Config
+	status   int
+	header   http.Header
+	body     []byte
+	duration time.Duration
+	err      error
+}
+
+func (d *DiffCommand) Run(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+
+	var (
+		left       = fs.String("left", "", "Left side: profile name or base URL (http[s]://...)")
+		right      = fs.String("right", "", "Right side: profile name or base URL (http[s]://...)")
+		method     = fs.String("method", "GET", "HTTP method")
+		urlStr     = fs.String("url", "", "Request path, relative to both sides' base URLs")
+		inlineJSON = fs.String("data", "", "JSON body, or @file / @- (stdin)")
+		allHeaders = fs.Bool("all-headers", false, "Compare every response header except Date, Content-Length and other volatile ones")
+		timeoutSec = fs.Int("timeout", 30, "Timeout in seconds")
+		insecure   = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
+		retries    = fs.Int("retries", 0, "Number of retries on failure (network/5xx)")
+		retryWait  = fs.Int("retry-delay", 1, "Delay between retries in seconds")
+		varsFile   = fs.String("vars-file", vars.DefaultFile, "Variables file for {{name}} placeholders")
+	)
+
+	headers := HeaderFlag{}
+	fs.Var(&headers, "header", "HTTP header 'Key: Value' sent to both sides (can be repeated)")
+	cliVars := VarFlag{}
+	fs.Var(&cliVars, "var", "Variable 'name=value' for {{name}} placeholders (can be repeated)")
+	var ignore ListFlag
+	fs.Var(&ignore, "ignore", "JSON path in the body left out of the comparison, e.g. $.id or $..createdAt (can be repeated)")
+	var compareHeaders ListFlag
+	fs.Var(&compareHeaders, "compare-header", "Response header to compare (can be repeated; default Content-Type)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *left == "" || *right == "" {
+		return fmt.Errorf("--left and --right are required")
+	}
+	if isAbsoluteURL(*urlStr) {
+		return fmt.Errorf("--url must be relative to the base URLs of --left and --right")
+	}
+	if *allHeaders && len(compareHeaders) > 0 {
+		return fmt.Errorf("use either --all-headers or --compare-header")
+	}
+	if len(compareHeaders) == 0 {
+		compareHeaders = ListFlag{"Content-Type"}
+	}
+	opts, err := newSnapshotOptions(ignore, nil, false)
+	if err != nil {
+		return fmt.Errorf("--ignore: %w", err)
+	}
+
+	// Placeholders are expanded once, so both sides get the same request
+	// even with {{uuid}} or {{now}}
+	variables, err := vars.LoadFile(*varsFile)
+	if err != nil {
+		return fmt.Errorf("load variables: %w", err)
+	}
+	for k, v := range cliVars {
+		variables[k] = v
+	}
+	path, err := vars.Expand(*urlStr, variables)
+	if err != nil {
+		return fmt.Errorf("--url: %w", err)
+	}
+	for k, v := range headers {
+		if headers[k], err = vars.Expand(v, variables); err != nil {
+			return fmt.Errorf("header %s: %w", k, err)
+		}
+	}
+	var body []byte
+	if *inlineJSON != "" {
+		data, fromSource, err := payload.ReadArg(*inlineJSON, os.Stdin)
+		if err != nil {
+			return fmt.Errorf("--data: %w", err)
+		}
+		// @file and @- content is sent byte for byte
+		if !fromSource {
+			if data, err = vars.Expand(data, variables); err != nil {
+				return fmt.Errorf("--data: %w", err)
+			}
+			if !json.Valid([]byte(data)) {
+				return fmt.Errorf("--data is not valid JSON")
+			}
+		}
+		body = []byte(data)
+	}
+
+	sides := []*diffSide{{name: "left"}, {name: "right"}}
+	for i, target := range []string{*left, *right} {
+		in := requestInput{
+			Method:   strings.ToUpper(*method),
+			URL:      path,
+			Headers:  headers,
+			Body:     body,
+			Timeout:  time.Duration(*timeoutSec) * time.Second,
+			Insecure: *insecure,
+		}
+		if isAbsoluteURL(target) {
+			in.URL = strings.TrimRight(target, "/")
+			if path != "" {
+				in.URL += "/" + strings.TrimLeft(path, "/")
+			}
+		} else {
+			in.Profile = target
+		}
+		resolved, err := resolveRequest(in)
+		if err != nil {
+			return fmt.Errorf("--%s: %w", sides[i].name, err)
+		}
+		if !isAbsoluteURL(resolved.Config.URL) {
+			return fmt.Errorf("--%s: profile %q has no base URL", sides[i].name, target)
+		}
+		sides[i].config = resolved.Config
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range sides {
+		wg.Add(1)
+		go func(s *diffSide) {
+			defer wg.Done()
+			d.send(s, *retries, time.Duration(*retryWait)*time.Second)
+		}(s)
+	}
+	wg.Wait()
+
+	var transportErr error
+	for _, s := range sides {
+		if s.err != nil {
+			fmt.Printf("%-6s %s %s -> %v\n", s.name, s.config.Method, s.config.URL, s.err)
+			if transportErr == nil {
+				transportErr = fmt.Errorf("%s: %w", s.name, s.err)
+			}
+			continue
+		}
+		fmt.Printf("%-6s %s %s -> %d %s (%d ms)\n", s.name, s.config.Method, s.config.URL,
+			s.status, http.StatusText(s.status), s.duration.Milliseconds())
+	}
+	if transportErr != nil {
+		return &ExitError{Code: exitTransport, Err: transportErr}
+	}
+
+	l := toJSONValue(diffView(opts, sides[0], compareHeaders, *allHeaders))
+	r := toJSONValue(diffView(opts, sides[1], compareHeaders, *allHeaders))
+	changes := jsondiff.Diff(l, r)
+
+	fmt.Println()
+	if len(changes) == 0 {
+		fmt.Println("No differences")
+		return nil
+	}
+	fmt.Println("Differences (left -> right):")
+	for _, c := range changes {
+		fmt.Printf("  %s\n", c)
+	}
+	return &ExitError{Code: exitAssertion, Err: fmt.Errorf("responses differ in %d place(s)", len(changes))}
+}
+
+// send performs the request of one side. A 5xx after the last retry is
+// a response like any other here.
+func (d *DiffCommand) send(s *diffSide, retries int, delay time.Duration) {
+	start := time.Now()
+	resp, err := sendWithRetry(d.Factory, s.config, retries, delay)
+	var failed *statusError
+	if errors.As(err, &failed) {
+		resp, err = failed.resp, nil
+	}
+	if err != nil {
+		s.err = err
+		return
+	}
+	defer resp.Body.Close()
+
+	if s.body, err = io.ReadAll(resp.Body); err != nil {
+		s.err = fmt.Errorf("read response: %w", err)
+		return
+	}
+	s.status, s.header, s.duration = resp.StatusCode, resp.Header, time.Since(start)
+}
+
+// diffView is what is compared of a response: the status, the selected
+// headers (all but the volatile ones with all) and the masked body.
+func diffView(o snapshotOptions, s *diffSide, names []string, all bool) snapshotFile {
+	view := o.normalize(s.status, s.header, s.body)
+	if all {
+		return view
+	}
+	selected := make(map[string]string, len(names))
+	for _, name := range names {
+		key := http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if v := s.header.Values(key); len(v) > 0 {
+			selected[key] = strings.Join(v, ", ")
+		}
+	}
+	view.Headers = selected
+	return view
+}
+
+func isAbsoluteURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/output"
+)
+
+// printDryRun prints the fully resolved request and where each header and
+// auth value came from, without sending anything. Credential headers are
+// shown as "(set)" unless showSecrets is true.
+func printDryRun(res *resolvedRequest, req *http.Request, showSecrets bool) {
+	fmt.Println("=== Request (dry run, not sent) ===")
+	fmt.Printf("%s %s\n", req.Method, req.URL.String())
+
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := strings.Join(req.Header[k], ", ")
+		if !showSecrets && output.IsSecretHeader(k) {
+			value = "(set)"
+		}
+		fmt.Printf("%s: %s\n", k, value)
+	}
+	if len(res.Config.Body) > 0 {
+		fmt.Println()
+		fmt.Println("Body:")
+		fmt.Println(bodyPreview(res.Config.Body))
+	} else if res.BodySummary != "" {
+		fmt.Println()
+		fmt.Println("Body:")
+		fmt.Println(res.BodySummary)
+	}
+
+	fmt.Println("\n=== Sources ===")
+	fmt.Printf("URL   : %s\n", res.URLSource)
+	fmt.Printf("Auth  : %s (from %s)\n", res.AuthType, res.AuthSources["type"])
+	switch res.AuthType {
+	case "basic":
+		fmt.Printf("  User  : %s\n", res.AuthSources["user"])
+		fmt.Printf("  Pass  : %s\n", res.AuthSources["pass"])
+	case "bearer":
+		fmt.Printf("  Token : %s\n", res.AuthSources["token"])
+	}
+	if len(keys) > 0 {
+		fmt.Println("Headers:")
+		for _, k := range keys {
+			fmt.Printf("  %-20s <- %s\n", k, res.headerSource(k, req))
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package command
+
+// Exit codes shared by the request commands (JSON-RPC codes are in rpc.go).
+const (
+	exitTransport     = 3 // no response: connection, TLS or timeout error
+	exitHTTPStatus    = 4 // 4xx/5xx response and no --expect-status
+	exitSchemaInvalid = 5 // body does not match --request-schema/--response-schema, or --strict OpenAPI violations
+	exitAssertion     = 6 // an --expect-* check failed, or --snapshot / diff found differences
+)
+
+// ExitError makes main exit with a specific status instead of 1.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
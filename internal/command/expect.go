@@ -0,0 +1,117 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/assert"
+)
+
+// buildExpectations turns the --expect-* flags into assertions:
+//
+//	--expect-status 2xx | 201,204 | 200-299
+//	--expect-header Name           header is present
+//	--expect-header "Name: value"  header equals value
+//	--expect-header "Name: /re/"   header matches a regular expression
+//	--expect-json '$.id'           path exists
+//	--expect-json '$.id=42'        value equals (JSON literal, else text)
+//	--expect-json '$.id="42"'      value is exactly the string "42"
+//	--expect-json '$.name=~^a'     value matches a regular expression
+func buildExpectations(status string, headers, paths []string) ([]assert.Assertion, error) {
+	var out []assert.Assertion
+	if status != "" {
+		a, err := assert.Status(status)
+		if err != nil {
+			return nil, fmt.Errorf("--expect-status: %w", err)
+		}
+		out = append(out, a)
+	}
+
+	for _, h := range headers {
+		name, value, hasValue := strings.Cut(h, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if name == "" {
+			return nil, fmt.Errorf("--expect-header %q: missing header name", h)
+		}
+		m := assert.Exists(true)
+		if hasValue {
+			m = assert.Equals(value)
+			if len(value) >= 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+				re, err := assert.Matches(value[1 : len(value)-1])
+				if err != nil {
+					return nil, fmt.Errorf("--expect-header %q: %w", h, err)
+				}
+				m = re
+			}
+		}
+		out = append(out, assert.Header(name, m))
+	}
+
+	for _, p := range paths {
+		path, op, value := splitExpectJSON(p)
+		if path == "" {
+			return nil, fmt.Errorf("--expect-json %q: missing JSON path", p)
+		}
+		var m assert.Matcher
+		switch op {
+		case "":
+			m = assert.Exists(true)
+		case "=~":
+			re, err := assert.Matches(value)
+			if err != nil {
+				return nil, fmt.Errorf("--expect-json %q: %w", p, err)
+			}
+			m = re
+		default:
+			var want interface{}
+			if err := json.Unmarshal([]byte(value), &want); err != nil {
+				want = value // plain text: --expect-json '$.name=alice'
+			}
+			m = assert.Equals(want)
+			if _, quoted := want.(string); quoted && value != want {
+				m = assert.Exactly(want) // '$.id="42"' asks for a string
+			}
+		}
+		out = append(out, assert.JSON(path, m))
+	}
+	return out, nil
+}
+
+// splitExpectJSON splits "path=value" / "path=~re" at the first "=" outside
+// brackets, so filters like $.a[?(@.b==1)] stay part of the path.
+func splitExpectJSON(s string) (path, op, value string) {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '=':
+			if depth > 0 {
+				continue
+			}
+			if strings.HasPrefix(s[i:], "=~") {
+				return strings.TrimSpace(s[:i]), "=~", s[i+2:]
+			}
+			return strings.TrimSpace(s[:i]), "=", s[i+1:]
+		}
+	}
+	return strings.TrimSpace(s), "", ""
+}
+
+// checkExpectations lists failed expectations on stderr and returns an
+// ExitError with exitAssertion.
+func checkExpectations(assertions []assert.Assertion, r *assert.Response) error {
+	failures := assert.Check(r, assertions)
+	if len(failures) == 0 {
+		return nil
+	}
+	fmt.Fprintln(os.Stderr, "Expectations failed:")
+	for _, f := range failures {
+		fmt.Fprintf(os.Stderr, "  %s\n", f)
+	}
+	return &ExitError{Code: exitAssertion, Err: fmt.Errorf("%d of %d expectation(s) failed", len(failures), len(assertions))}
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go-rest-api-cli-demo/internal/filter"
+)
+
+// applyFilter runs a --filter over a JSON response body and renders the
+// result. A single output is printed as JSON; several outputs are collected
+// into an array. With raw, each output goes on its own line and string
+// outputs are printed without quotes (like jq -r).
+func applyFilter(f *filter.Filter, body []byte, pretty, raw bool) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("--filter needs a JSON response: %w", err)
+	}
+
+	results, err := f.Apply(doc)
+	if err != nil {
+		return nil, fmt.Errorf("--filter: %w", err)
+	}
+
+	marshal := func(v interface{}) ([]byte, error) {
+		if pretty {
+			return json.MarshalIndent(v, "", "  ")
+		}
+		return json.Marshal(v)
+	}
+
+	if raw {
+		var buf bytes.Buffer
+		for i, r := range results {
+			if i > 0 {
+				buf.WriteByte('\n')
+			}
+			if s, ok := r.(string); ok {
+				buf.WriteString(s)
+				continue
+			}
+			data, err := marshal(r)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(data)
+		}
+		return buf.Bytes(), nil
+	}
+
+	var out interface{} = results
+	if len(results) == 1 {
+		out = results[0]
+	} else if results == nil {
+		out = []interface{}{}
+	}
+	return marshal(out)
+}
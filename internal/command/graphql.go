@@ -0,0 +1,358 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go-rest-api-cli-demo/internal/filter"
+	"go-rest-api-cli-demo/internal/graphql"
+	"go-rest-api-cli-demo/internal/httpclient"
+)
+
+// GraphQLCommand = "graphql" subcommand: queries, mutations and schema
+// introspection against a GraphQL endpoint.
+type GraphQLCommand struct {
+	Factory httpclient.Factory
+}
+
+func NewGraphQLCommand(factory httpclient.Factory) *GraphQLCommand {
+	return &GraphQLCommand{Factory: factory}
+}
+
+func (g *GraphQLCommand) Name() string { return "graphql" }
+func (g *GraphQLCommand) Description() string {
+	return "Send GraphQL queries/mutations or dump the schema as SDL"
+}
+
+// gqlTarget is the endpoint and transport settings shared by all requests of
+// one invocation.
+type gqlTarget struct {
+	input     requestInput
+	retries   int
+	retryWait time.Duration
+}
+
+func (g *GraphQLCommand) Run(args []string) error {
+	// Allow "graphql FILE.graphql [flags]" as well as "--query-file FILE"
+	var positional string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("graphql", flag.ContinueOnError)
+
+	var (
+		urlStr      = fs.String("url", "", "GraphQL endpoint (relative with --profile, default /graphql)")
+		profileName = fs.String("profile", "", "Profile name to use from config")
+		queryStr    = fs.String("query", "", "Query or mutation document")
+		queryFile   = fs.String("query-file", positional, "File with the query document (.graphql)")
+		operation   = fs.String("operation", "", "Operation to run when the document has several")
+		varsJSON    = fs.String("variables", "", "Variables as a JSON object")
+		varsFile    = fs.String("variables-file", "", "File with variables as a JSON object")
+		persisted   = fs.Bool("persisted", false, "Use Automatic Persisted Queries (send the sha256 hash first)")
+		hash        = fs.String("hash", "", "Send only this persisted-query sha256 hash (no query text)")
+		schema      = fs.Bool("schema", false, "Run introspection and print the schema as SDL")
+		schemaJSON  = fs.Bool("schema-json", false, "With --schema, print the raw introspection JSON instead")
+
+		timeoutSec = fs.Int("timeout", 30, "Timeout in seconds")
+		insecure   = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
+		retries    = fs.Int("retries", 0, "Number of retries on failure (network/5xx)")
+		retryWait  = fs.Int("retry-delay", 1, "Delay between retries in seconds")
+
+		authType = fs.String("auth", "none", "Auth: none|basic|bearer")
+		user     = fs.String("user", "", "Username for basic auth")
+		pass     = fs.String("pass", "", "Password for basic auth")
+		token    = fs.String("token", "", "Bearer token")
+
+		pretty    = fs.Bool("pretty", false, "Pretty-print the JSON response")
+		dataOnly  = fs.Bool("data-only", false, "Print only the data field of the response")
+		filterStr = fs.String("filter", "", "JSONPath or jq-style expression applied to the response")
+		raw       = fs.Bool("raw", false, "With --filter, print strings without quotes")
+		outPath   = fs.String("out", "", "Write the printed result to file")
+	)
+
+	headers := HeaderFlag{}
+	fs.Var(&headers, "header", "HTTP header 'Key: Value' (can be repeated)")
+	gqlVars := VarFlag{}
+	fs.Var(&gqlVars, "var", "GraphQL variable 'name=value'; JSON values (42, true, {...}) are typed (can be repeated)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *queryFile == "" && fs.NArg() > 0 {
+		*queryFile = fs.Arg(0) // file given after the flags
+	}
+
+	endpoint := *urlStr
+	if endpoint == "" {
+		if *profileName == "" {
+			return fmt.Errorf("--url is required")
+		}
+		endpoint = "/graphql"
+	}
+
+	target := gqlTarget{
+		input: requestInput{
+			Method:   "POST",
+			URL:      endpoint,
+			Profile:  *profileName,
+			Headers:  headers,
+			AuthType: *authType,
+			User:     *user,
+			Pass:     *pass,
+			Token:    *token,
+			Timeout:  time.Duration(*timeoutSec) * time.Second,
+			Insecure: *insecure,
+		},
+		retries:   *retries,
+		retryWait: time.Duration(*retryWait) * time.Second,
+	}
+
+	if *schema {
+		return g.dumpSchema(target, *schemaJSON, *outPath)
+	}
+
+	// Build the request
+	req := graphql.Request{OperationName: *operation}
+	switch {
+	case *queryStr != "" && *queryFile != "":
+		return fmt.Errorf("use either --query or a query file, not both")
+	case *queryStr != "":
+		req.Query = *queryStr
+	case *queryFile != "":
+		data, err := os.ReadFile(*queryFile)
+		if err != nil {
+			return fmt.Errorf("reading query file: %w", err)
+		}
+		req.Query = string(data)
+	case *hash == "":
+		return fmt.Errorf("a query is required (graphql FILE, --query-file, --query or --hash)")
+	}
+	if req.OperationName == "" {
+		if ops := graphql.Operations(req.Query); len(ops) > 1 {
+			return fmt.Errorf("the document has several operations (%s); choose one with --operation", strings.Join(ops, ", "))
+		}
+	}
+
+	variables, err := graphQLVariables(*varsFile, *varsJSON, gqlVars)
+	if err != nil {
+		return err
+	}
+	req.Variables = variables
+
+	var respBody []byte
+	switch {
+	case *hash != "" || *persisted:
+		respBody, err = g.sendPersisted(target, req, *hash)
+	default:
+		respBody, err = g.send(target, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	var resp graphql.Response
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("response is not GraphQL JSON: %w\n%s", err, respBody)
+	}
+
+	// Print the response (or a part of it)
+	toPrint := respBody
+	if *dataOnly {
+		toPrint = resp.Data
+		if len(toPrint) == 0 {
+			toPrint = []byte("null")
+		}
+	}
+	if *filterStr != "" {
+		f, err := filter.Compile(*filterStr)
+		if err != nil {
+			return fmt.Errorf("--filter: %w", err)
+		}
+		if toPrint, err = applyFilter(f, toPrint, *pretty, *raw); err != nil {
+			return err
+		}
+	} else if *pretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, toPrint, "", "  "); err == nil {
+			toPrint = buf.Bytes()
+		}
+	}
+	fmt.Println(string(toPrint))
+
+	if *outPath != "" {
+		if err := os.WriteFile(*outPath, toPrint, 0o644); err != nil {
+			return fmt.Errorf("failed to write response to file: %w", err)
+		}
+	}
+
+	// errors[] means the operation (partly) failed, even with HTTP 200
+	if len(resp.Errors) > 0 {
+		for _, e := range resp.Errors {
+			fmt.Fprintf(os.Stderr, "GraphQL error: %s\n", e)
+		}
+		return fmt.Errorf("graphql: response contains %d error(s)", len(resp.Errors))
+	}
+	return nil
+}
+
+// send posts one GraphQL request and returns the response body. HTTP error
+// statuses are returned as the body only when it carries GraphQL errors[];
+// otherwise they fail with exitHTTPStatus.
+func (g *GraphQLCommand) send(t gqlTarget, req graphql.Request) ([]byte, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling request: %w", err)
+	}
+	in := t.input
+	in.Body = body
+
+	resolved, err := resolveRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := resolved.Config.Headers["Accept"]; !ok {
+		resolved.Config.Headers["Accept"] = "application/graphql-response+json, application/json"
+	}
+
+	resp, err := sendWithRetry(g.Factory, resolved.Config, t.retries, t.retryWait)
+	var failed *statusError
+	if errors.As(err, &failed) {
+		resp, err = failed.resp, nil // a 5xx may still explain itself in errors[]
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		var gr graphql.Response
+		if json.Unmarshal(respBody, &gr) != nil || len(gr.Errors) == 0 {
+			return nil, &ExitError{Code: exitHTTPStatus, Err: fmt.Errorf("HTTP %s: %s", resp.Status, strings.TrimSpace(string(respBody)))}
+		}
+	}
+	return respBody, nil
+}
+
+// sendPersisted sends only the query hash; when the server does not know it
+// yet and the query text is available, it registers it by resending the
+// hash together with the query (Automatic Persisted Queries).
+func (g *GraphQLCommand) sendPersisted(t gqlTarget, req graphql.Request, hash string) ([]byte, error) {
+	if hash == "" {
+		hash = graphql.Hash(req.Query)
+	}
+	query := req.Query
+
+	req.Query = ""
+	req.Extensions = graphql.PersistedExtension(hash)
+	body, err := g.send(t, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp graphql.Response
+	if json.Unmarshal(body, &resp) != nil || !resp.IsPersistedQueryNotFound() {
+		return body, nil
+	}
+	if query == "" {
+		return nil, fmt.Errorf("persisted query %s is not known to the server (pass the query to register it)", hash)
+	}
+	if graphql.Hash(query) != hash {
+		return nil, fmt.Errorf("--hash does not match the sha256 of the query")
+	}
+
+	fmt.Fprintf(os.Stderr, "Persisted query %s not found; registering it\n", hash[:12])
+	req.Query = query
+	return g.send(t, req)
+}
+
+// dumpSchema runs the introspection query and prints SDL (or the raw JSON).
+func (g *GraphQLCommand) dumpSchema(t gqlTarget, asJSON bool, outPath string) error {
+	body, err := g.send(t, graphql.Request{Query: graphql.IntrospectionQuery, OperationName: "IntrospectionQuery"})
+	if err != nil {
+		return err
+	}
+
+	var resp graphql.Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("response is not GraphQL JSON: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		for _, e := range resp.Errors {
+			fmt.Fprintf(os.Stderr, "GraphQL error: %s\n", e)
+		}
+		return fmt.Errorf("introspection failed with %d error(s)", len(resp.Errors))
+	}
+
+	var out []byte
+	if asJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, resp.Data, "", "  "); err != nil {
+			return err
+		}
+		out = buf.Bytes()
+	} else {
+		sdl, err := graphql.SchemaSDL(resp.Data)
+		if err != nil {
+			return err
+		}
+		out = []byte(strings.TrimRight(sdl, "\n"))
+	}
+
+	if outPath != "" {
+		if err := os.WriteFile(outPath, append(out, '\n'), 0o644); err != nil {
+			return fmt.Errorf("write schema: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Schema written to %s\n", outPath)
+		return nil
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// graphQLVariables merges the variables file, --variables and --var (in that
+// order of precedence, lowest first).
+func graphQLVariables(file, inline string, cli VarFlag) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading variables-file: %w", err)
+		}
+		if err := json.Unmarshal(data, &vars); err != nil {
+			return nil, fmt.Errorf("variables-file must be a JSON object: %w", err)
+		}
+	}
+	if inline != "" {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(inline), &m); err != nil {
+			return nil, fmt.Errorf("--variables must be a JSON object: %w", err)
+		}
+		for k, v := range m {
+			vars[k] = v
+		}
+	}
+	for k, v := range cli {
+		var typed interface{}
+		if err := json.Unmarshal([]byte(v), &typed); err == nil {
+			vars[k] = typed
+		} else {
+			vars[k] = v
+		}
+	}
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	return vars, nil
+}
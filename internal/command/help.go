@@ -29,6 +29,10 @@ func (h *HelpCommand) Run(args []string) error {
 	fmt.Printf("  %s call --method GET --url \"https://api.agify.io/?name=meelad\"\n", h.appName)
 	fmt.Printf("  %s profile add --name myapi --base-url https://api.example.com --auth bearer --token TOKEN\n", h.appName)
 	fmt.Printf("  %s call --profile myapi --method GET --url \"/v1/users\" --pretty\n", h.appName)
+	fmt.Printf("  %s request run --name get-user --var id=42\n", h.appName)
+	fmt.Printf("  %s openapi call getPet --profile myapi --petId 42\n", h.appName)
+	fmt.Printf("  %s diff --left staging --right prod --url /v1/users/42 --ignore '$.updatedAt'\n", h.appName)
+	fmt.Printf("  %s ws --profile myapi --url /v1/stream --send '{\"type\":\"subscribe\"}'\n", h.appName)
 
 	return nil
 }
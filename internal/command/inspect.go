@@ -65,6 +65,9 @@ func (i *InspectCommand) inspectProfiles() error {
 		fmt.Printf("- %s\n", name)
 		fmt.Printf("  Base URL : %s\n", pf.BaseURL)
 		fmt.Printf("  Auth     : %s\n", authInfo)
+		if pf.OpenAPI != "" {
+			fmt.Printf("  OpenAPI  : %s\n", pf.OpenAPI)
+		}
 		if len(pf.Headers) > 0 {
 			fmt.Println("  Headers  :")
 			for k, v := range pf.Headers {
@@ -111,6 +114,9 @@ func (i *InspectCommand) inspectProfile(args []string) error {
 	if pf.Token != "" {
 		fmt.Printf("  Token    : (set)\n")
 	}
+	if pf.OpenAPI != "" {
+		fmt.Printf("  OpenAPI  : %s\n", pf.OpenAPI)
+	}
 	if len(pf.Headers) > 0 {
 		fmt.Println("  Headers  :")
 		for k, v := range pf.Headers {
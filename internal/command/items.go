@@ -0,0 +1,244 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/payload"
+	"go-rest-api-cli-demo/internal/vars"
+)
+
+// itemKind is the meaning of a positional request item, picked by its
+// separator.
+type itemKind int
+
+const (
+	itemHeader    itemKind = iota // Header:Value
+	itemQuery                     // name==value
+	itemField                     // name=value (JSON string)
+	itemJSON                      // name:=42 (raw JSON)
+	itemFieldFile                 // name=@file (file content as a string)
+	itemJSONFile                  // name:=@file.json (file content as JSON)
+)
+
+// itemSeparators are tried at each position, longest first, so "a:=1" is a
+// JSON field and not the header "a" with value "=1".
+var itemSeparators = []struct {
+	sep  string
+	kind itemKind
+}{
+	{":=@", itemJSONFile},
+	{"==", itemQuery},
+	{":=", itemJSON},
+	{"=@", itemFieldFile},
+	{"=", itemField},
+	{":", itemHeader},
+}
+
+// requestItem is one positional item of "call", e.g. user.name=Ada.
+type requestItem struct {
+	kind  itemKind
+	key   string // raw key; backslash escapes are kept for body paths
+	value string
+}
+
+// isBody reports whether the item sets a field of the JSON body.
+func (it requestItem) isBody() bool {
+	return it.kind == itemField || it.kind == itemJSON || it.kind == itemFieldFile || it.kind == itemJSONFile
+}
+
+// parseRequestItem splits an item at its first unescaped separator. ok is
+// false when arg does not have the shape of an item: no separator, no name
+// before it, or a "Header:Value" that looks like a URL or host:port.
+func parseRequestItem(arg string) (it requestItem, ok bool) {
+	for i := 0; i < len(arg); i++ {
+		if arg[i] == '\\' {
+			i++ // escaped character, e.g. "a\=b=c"
+			continue
+		}
+		for _, s := range itemSeparators {
+			if !strings.HasPrefix(arg[i:], s.sep) {
+				continue
+			}
+			it = requestItem{kind: s.kind, key: arg[:i], value: arg[i+len(s.sep):]}
+			if i == 0 || it.kind == itemHeader && !isHeaderItem(it) {
+				return requestItem{}, false
+			}
+			return it, true
+		}
+	}
+	return requestItem{}, false
+}
+
+// parseRequestItems parses the positional arguments of a call; an argument
+// that is not an item is an error.
+func parseRequestItems(args []string) ([]requestItem, error) {
+	items := make([]requestItem, 0, len(args))
+	for _, a := range args {
+		it, ok := parseRequestItem(a)
+		if !ok {
+			return nil, fmt.Errorf("argument %q is not a request item (name=value, name:=json, name==query or Header:Value); use --url for the URL", a)
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// isHeaderItem reports whether a "name:value" item is a header rather than
+// a URL ("http://..."), a host with a port ("localhost:8080",
+// "api.example.com:443") or a host:port/path.
+func isHeaderItem(it requestItem) bool {
+	if !isHeaderToken(it.key) || strings.HasPrefix(it.value, "//") {
+		return false
+	}
+	if strings.EqualFold(it.key, "localhost") || strings.Contains(it.key, ".") {
+		return false
+	}
+	// "api:8080/v1": a port and a path
+	rest := strings.TrimLeft(it.value, "0123456789")
+	return rest == it.value || !strings.HasPrefix(rest, "/")
+}
+
+// isHeaderToken reports whether s is a valid header name (RFC 9110 token).
+func isHeaderToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// unescapeItemKey drops the backslashes of a header or query name.
+func unescapeItemKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] == '\\' && i+1 < len(key) {
+			i++
+		}
+		b.WriteByte(key[i])
+	}
+	return b.String()
+}
+
+// applyURLItems adds Header:Value items to headers and name==value items to
+// the query string of rawURL, expanding {{name}} placeholders.
+func applyURLItems(items []requestItem, rawURL string, headers map[string]string, variables map[string]string) (string, error) {
+	var query []string
+	for _, it := range items {
+		if it.kind != itemHeader && it.kind != itemQuery {
+			continue
+		}
+		value, err := vars.Expand(it.value, variables)
+		if err != nil {
+			return "", fmt.Errorf("request item %s: %w", it.key, err)
+		}
+		key := unescapeItemKey(it.key)
+		if it.kind == itemHeader {
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			continue
+		}
+		query = append(query, url.QueryEscape(key)+"="+url.QueryEscape(value))
+	}
+	if len(query) == 0 {
+		return rawURL, nil
+	}
+
+	// Keep any existing query and fragment as they are
+	base, fragment, hasFragment := strings.Cut(rawURL, "#")
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+		if strings.HasSuffix(base, "?") || strings.HasSuffix(base, "&") {
+			sep = ""
+		}
+	}
+	out := base + sep + strings.Join(query, "&")
+	if hasFragment {
+		out += "#" + fragment
+	}
+	return out, nil
+}
+
+// applyBodyItems sets the body items on body, creating nested objects and
+// arrays for paths such as user.address.city or tags[].
+func applyBodyItems(items []requestItem, body map[string]interface{}, variables map[string]string) error {
+	for _, it := range items {
+		if !it.isBody() {
+			continue
+		}
+		text, err := vars.Expand(it.value, variables)
+		if err != nil {
+			return fmt.Errorf("request item %s: %w", it.key, err)
+		}
+		if it.kind == itemFieldFile || it.kind == itemJSONFile {
+			data, err := os.ReadFile(text)
+			if err != nil {
+				return fmt.Errorf("request item %s: %w", it.key, err)
+			}
+			if text, err = vars.Expand(string(data), variables); err != nil {
+				return fmt.Errorf("request item %s: %w", it.key, err)
+			}
+		}
+
+		var value interface{} = text
+		if it.kind == itemJSON || it.kind == itemJSONFile {
+			if err := json.Unmarshal([]byte(text), &value); err != nil {
+				return fmt.Errorf("request item %s: value is not valid JSON: %w", it.key, err)
+			}
+		}
+		if err := payload.SetPath(body, it.key, value); err != nil {
+			return fmt.Errorf("request item: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseInterleaved parses flags that may be mixed with positional arguments
+// ("call --url /x name=Ada --pretty") and returns the positional ones.
+// Everything after "--" is positional.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		for len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+			positional = append(positional, rest[0])
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		args = rest
+	}
+}
+
+// jsonKind names the type of a decoded JSON value for error messages.
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return "null"
+}
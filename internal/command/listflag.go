@@ -0,0 +1,31 @@
+package command
+
+import (
+	"flag"
+	"strings"
+)
+
+// ListFlag implements flag.Value for repeatable flags whose values keep
+// their order (e.g. --send).
+type ListFlag []string
+
+func (l *ListFlag) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *ListFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// flagPassed reports whether the flag was given on the command line (as
+// opposed to holding its default).
+func flagPassed(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
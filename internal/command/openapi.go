@@ -0,0 +1,548 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	cfgstore "go-rest-api-cli-demo/internal/config"
+	"go-rest-api-cli-demo/internal/openapi"
+)
+
+// OpenAPICommand = "openapi" subcommand: browse an OpenAPI 3 document, run
+// its operations by operationId and create profiles from it.
+type OpenAPICommand struct {
+	call *CallCommand
+}
+
+func NewOpenAPICommand(call *CallCommand) *OpenAPICommand {
+	return &OpenAPICommand{call: call}
+}
+
+func (o *OpenAPICommand) Name() string { return "openapi" }
+func (o *OpenAPICommand) Description() string {
+	return "Use an OpenAPI 3 document (list/show/call operations, create profiles)"
+}
+
+func (o *OpenAPICommand) Run(args []string) error {
+	if len(args) == 0 {
+		o.printUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		return o.runList(args[1:])
+	case "show":
+		return o.runShow(args[1:])
+	case "call":
+		return o.runCall(args[1:])
+	case "profile":
+		return o.runProfile(args[1:])
+	default:
+		o.printUsage()
+		return fmt.Errorf("unknown openapi action: %s", args[0])
+	}
+}
+
+func (o *OpenAPICommand) printUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  go-rest-api-cli-demo openapi list (--spec FILE | --profile P) [--tag TAG]")
+	fmt.Println("  go-rest-api-cli-demo openapi show OPERATION (--spec FILE | --profile P)")
+	fmt.Println("  go-rest-api-cli-demo openapi call OPERATION (--spec FILE | --profile P) [--server N|URL] [--PARAM value ...] [--param name=value ...] [call flags ...] [-- call flags]")
+	fmt.Println("  go-rest-api-cli-demo openapi profile --name NAME --spec FILE [--server N|URL] [--scheme NAME] [--token T | --user U --pass P | --api-key K]")
+	fmt.Println()
+	fmt.Println("OPERATION is an operationId or \"METHOD /path\".")
+}
+
+// loadSpec loads the document from --spec, or from the profile's link.
+func loadSpec(specPath, profileName string) (*openapi.Document, error) {
+	if specPath == "" && profileName != "" {
+		cfg, err := cfgstore.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+		pf, ok := cfg.Profiles[profileName]
+		if !ok {
+			return nil, fmt.Errorf("profile %q not found", profileName)
+		}
+		if pf.OpenAPI == "" {
+			return nil, fmt.Errorf("profile %q has no OpenAPI document; pass --spec or link one with \"openapi profile\"", profileName)
+		}
+		specPath = pf.OpenAPI
+	}
+	if specPath == "" {
+		return nil, fmt.Errorf("--spec or --profile is required")
+	}
+	return openapi.Load(specPath)
+}
+
+func (o *OpenAPICommand) runList(args []string) error {
+	fs := flag.NewFlagSet("openapi list", flag.ContinueOnError)
+	specPath := fs.String("spec", "", "OpenAPI document (JSON or YAML)")
+	profileName := fs.String("profile", "", "Profile linked to an OpenAPI document")
+	tag := fs.String("tag", "", "Only operations with this tag")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	doc, err := loadSpec(*specPath, *profileName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s (OpenAPI %s)\n", doc.Title, doc.APIVersion, doc.Version)
+	if len(doc.Servers) > 0 {
+		fmt.Println("Servers:")
+		for i, s := range doc.Servers {
+			fmt.Printf("  [%d] %s  %s\n", i, s.Resolve(nil), s.Description)
+		}
+	}
+	fmt.Println("Operations:")
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, op := range doc.Operations {
+		if *tag != "" && !containsFold(op.Tags, *tag) {
+			continue
+		}
+		summary := op.Summary
+		if op.Deprecated {
+			summary = "(deprecated) " + summary
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", op.Method, op.Path, op.ID, summary)
+	}
+	return tw.Flush()
+}
+
+func (o *OpenAPICommand) runShow(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: openapi show OPERATION (--spec FILE | --profile P)")
+	}
+	id := args[0]
+
+	fs := flag.NewFlagSet("openapi show", flag.ContinueOnError)
+	specPath := fs.String("spec", "", "OpenAPI document (JSON or YAML)")
+	profileName := fs.String("profile", "", "Profile linked to an OpenAPI document")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	doc, err := loadSpec(*specPath, *profileName)
+	if err != nil {
+		return err
+	}
+	op, err := doc.Find(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s  %s %s\n", op.ID, op.Method, op.Path)
+	if op.Summary != "" {
+		fmt.Printf("  %s\n", op.Summary)
+	}
+	if op.Deprecated {
+		fmt.Println("  Deprecated")
+	}
+	if len(op.Tags) > 0 {
+		fmt.Printf("  Tags: %s\n", strings.Join(op.Tags, ", "))
+	}
+
+	if len(op.Parameters) > 0 {
+		fmt.Println("  Parameters:")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, p := range op.Parameters {
+			req := ""
+			if p.Required {
+				req = "required"
+			}
+			fmt.Fprintf(tw, "    --%s\t%s\t%s\t%s\t%s\n", p.Name, p.In, openapi.SchemaType(p.Schema), req, p.Description)
+		}
+		tw.Flush()
+	}
+
+	if op.RequestBody != nil {
+		req := ""
+		if op.RequestBody.Required {
+			req = " (required)"
+		}
+		fmt.Printf("  Body%s:\n", req)
+		for _, mt := range sortedKeys(op.RequestBody.Content) {
+			fmt.Printf("    %s: %s\n", mt, openapi.SchemaType(op.RequestBody.Content[mt]))
+		}
+	}
+
+	if len(op.Responses) > 0 {
+		fmt.Println("  Responses:")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, code := range sortedKeys(op.Responses) {
+			r := op.Responses[code]
+			var types []string
+			for _, mt := range sortedKeys(r.Content) {
+				types = append(types, mt+": "+openapi.SchemaType(r.Content[mt]))
+			}
+			fmt.Fprintf(tw, "    %s\t%s\t%s\n", code, r.Description, strings.Join(types, ", "))
+		}
+		tw.Flush()
+	}
+
+	if sec := doc.EffectiveSecurity(op); len(sec) > 0 {
+		var alts []string
+		for _, req := range sec {
+			alts = append(alts, strings.Join(sortedKeys(req), " + "))
+		}
+		fmt.Printf("  Security: %s\n", strings.Join(alts, " or "))
+	}
+	return nil
+}
+
+// runCall turns an operation into a call: the method and path come from
+// the spec, parameters from --NAME/--param flags, everything else (body,
+// auth, output flags) is passed on to "call".
+func (o *OpenAPICommand) runCall(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: openapi call OPERATION (--spec FILE | --profile P) [--PARAM value ...] [call flags ...]")
+	}
+	id := args[0]
+	args = args[1:]
+
+	// Everything after "--" goes to call untouched
+	var passthrough []string
+	for i, a := range args {
+		if a == "--" {
+			args, passthrough = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	// Find the document and the operation first: they define the flags
+	located, _ := splitOwnFlags(args, "spec", "profile")
+	pre := flag.NewFlagSet("openapi call", flag.ContinueOnError)
+	specPath := pre.String("spec", "", "OpenAPI document (JSON or YAML)")
+	profileName := pre.String("profile", "", "Profile (base URL, auth; may link the OpenAPI document)")
+	if err := pre.Parse(located); err != nil {
+		return err
+	}
+	doc, err := loadSpec(*specPath, *profileName)
+	if err != nil {
+		return err
+	}
+	op, err := doc.Find(id)
+	if err != nil {
+		return err
+	}
+
+	names := []string{"spec", "server", "param"}
+	for _, p := range op.Parameters {
+		names = append(names, p.Name)
+	}
+	own, rest := splitOwnFlags(args, names...)
+
+	fs := flag.NewFlagSet("openapi call", flag.ContinueOnError)
+	fs.String("spec", "", "OpenAPI document (JSON or YAML)")
+	server := fs.String("server", "", "Server index in the spec, or a base URL (default: the profile's base URL, else server 0)")
+	params := VarFlag{}
+	fs.Var(&params, "param", "Parameter 'name=value' (can be repeated)")
+	values := map[string]*string{}
+	for _, p := range op.Parameters {
+		if _, dup := values[p.Name]; dup || p.Name == "spec" || p.Name == "server" || p.Name == "param" {
+			continue // reachable through --param
+		}
+		values[p.Name] = fs.String(p.Name, "", fmt.Sprintf("%s parameter", p.In))
+	}
+	if err := fs.Parse(own); err != nil {
+		return err
+	}
+	for name, v := range values {
+		if flagPassed(fs, name) {
+			params[name] = *v
+		}
+	}
+
+	// Sort the values into path, query, header and cookie parameters
+	var (
+		pathValues = map[string]string{}
+		query      []string
+		headers    []string
+		cookies    []string
+		missing    []string
+	)
+	for _, p := range op.Parameters {
+		v, ok := params[p.Name]
+		if !ok {
+			if p.Required {
+				missing = append(missing, fmt.Sprintf("--%s (%s)", p.Name, p.In))
+			}
+			continue
+		}
+		switch p.In {
+		case "path":
+			pathValues[p.Name] = v
+		case "query":
+			for _, item := range queryValues(p, v) {
+				query = append(query, url.QueryEscape(p.Name)+"="+url.QueryEscape(item))
+			}
+		case "header":
+			headers = append(headers, p.Name+": "+v)
+		case "cookie":
+			cookies = append(cookies, p.Name+"="+v)
+		}
+	}
+	for name := range params {
+		if !hasParam(op, name) {
+			return fmt.Errorf("operation %s has no parameter %q (see \"openapi show %s\")", op.ID, name, op.ID)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required parameter(s): %s", strings.Join(missing, ", "))
+	}
+
+	path, err := openapi.Expand(op.Path, pathValues)
+	if err != nil {
+		return err
+	}
+	if len(query) > 0 {
+		path += "?" + strings.Join(query, "&")
+	}
+
+	target, err := operationURL(doc, *profileName, *server, path)
+	if err != nil {
+		return err
+	}
+
+	callArgs := []string{"--method", op.Method, "--url", target}
+	for _, h := range headers {
+		callArgs = append(callArgs, "--header", h)
+	}
+	if len(cookies) > 0 {
+		callArgs = append(callArgs, "--header", "Cookie: "+strings.Join(cookies, "; "))
+	}
+	callArgs = append(callArgs, rest...)
+	callArgs = append(callArgs, passthrough...)
+
+	if err := o.call.Run(callArgs); err != nil {
+		return fmt.Errorf("%s: %w", op.ID, err)
+	}
+	return nil
+}
+
+// queryValues splits comma-separated values of array parameters, which are
+// sent as repeated name=value pairs (form style, explode).
+func queryValues(p openapi.Parameter, v string) []string {
+	if s, ok := p.Schema.(map[string]interface{}); ok && s["type"] == "array" {
+		return strings.Split(v, ",")
+	}
+	return []string{v}
+}
+
+func hasParam(op *openapi.Operation, name string) bool {
+	for _, p := range op.Parameters {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// operationURL picks the URL for path: --server (index or URL) wins, then
+// a profile base URL (path stays relative), then the first spec server.
+func operationURL(doc *openapi.Document, profileName, server, path string) (string, error) {
+	if server == "" && profileName != "" {
+		cfg, err := cfgstore.Load()
+		if err != nil {
+			return "", fmt.Errorf("load config: %w", err)
+		}
+		if cfg.Profiles[profileName].BaseURL != "" {
+			return path, nil
+		}
+	}
+
+	base, err := serverURL(doc, server)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(base, "/") + path, nil
+}
+
+// serverURL resolves --server: an index into the spec's servers (default
+// 0) or a URL.
+func serverURL(doc *openapi.Document, server string) (string, error) {
+	if strings.Contains(server, "://") {
+		return server, nil
+	}
+	i := 0
+	if server != "" {
+		n, err := strconv.Atoi(server)
+		if err != nil {
+			return "", fmt.Errorf("--server must be an index or a URL")
+		}
+		i = n
+	}
+	if len(doc.Servers) == 0 {
+		return "", fmt.Errorf("the document has no servers; pass --server URL or use a profile with a base URL")
+	}
+	if i < 0 || i >= len(doc.Servers) {
+		return "", fmt.Errorf("--server %d: the document has %d server(s)", i, len(doc.Servers))
+	}
+	u := doc.Servers[i].Resolve(nil)
+	if !strings.Contains(u, "://") {
+		return "", fmt.Errorf("server %q is relative; pass --server URL", u)
+	}
+	return u, nil
+}
+
+// runProfile creates (or updates) a profile from the document: base URL from
+// the servers, auth strategy from the security schemes, and a link to the
+// document for "openapi call/list --profile".
+func (o *OpenAPICommand) runProfile(args []string) error {
+	fs := flag.NewFlagSet("openapi profile", flag.ContinueOnError)
+	name := fs.String("name", "", "Profile name (required)")
+	specPath := fs.String("spec", "", "OpenAPI document (required)")
+	server := fs.String("server", "", "Server index in the spec, or a base URL (default 0)")
+	scheme := fs.String("scheme", "", "Security scheme to use (default: the first global requirement)")
+	token := fs.String("token", "", "Bearer token (http bearer, oauth2, openIdConnect)")
+	user := fs.String("user", "", "Username (http basic)")
+	pass := fs.String("pass", "", "Password (http basic)")
+	apiKey := fs.String("api-key", "", "API key (apiKey schemes)")
+	headers := HeaderFlag{}
+	fs.Var(&headers, "header", "Extra default header 'Key: Value' (can be repeated)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || *specPath == "" {
+		return fmt.Errorf("--name and --spec are required")
+	}
+
+	doc, err := openapi.Load(*specPath)
+	if err != nil {
+		return err
+	}
+	base, err := serverURL(doc, *server)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := cfgstore.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	pf := cfg.Profiles[*name] // keep existing values when updating
+	pf.Name = *name
+	pf.BaseURL = strings.TrimRight(base, "/")
+	pf.OpenAPI = doc.Path
+	if pf.Headers == nil {
+		pf.Headers = map[string]string{}
+	}
+	for k, v := range headers {
+		pf.Headers[k] = v
+	}
+
+	sch, ok, err := pickScheme(doc, *scheme)
+	if err != nil {
+		return err
+	}
+	authNote := "none"
+	if ok {
+		authNote, err = applyScheme(&pf, sch, *token, *user, *pass, *apiKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg.Profiles[pf.Name] = pf
+	if err := cfgstore.Save(cfg); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+
+	fmt.Printf("Profile %q saved\n", pf.Name)
+	fmt.Printf("  Base URL : %s\n", pf.BaseURL)
+	fmt.Printf("  Auth     : %s\n", authNote)
+	fmt.Printf("  OpenAPI  : %s\n", pf.OpenAPI)
+	return nil
+}
+
+// pickScheme chooses the security scheme for a profile.
+func pickScheme(doc *openapi.Document, name string) (openapi.SecurityScheme, bool, error) {
+	if name != "" {
+		s, ok := doc.SecuritySchemes[name]
+		if !ok {
+			return s, false, fmt.Errorf("--scheme %q: not in components.securitySchemes (have: %s)", name, strings.Join(sortedKeys(doc.SecuritySchemes), ", "))
+		}
+		return s, true, nil
+	}
+	for _, req := range doc.Security {
+		for _, n := range sortedKeys(req) {
+			if s, ok := doc.SecuritySchemes[n]; ok {
+				return s, true, nil
+			}
+		}
+	}
+	if len(doc.SecuritySchemes) == 1 {
+		for _, s := range doc.SecuritySchemes {
+			return s, true, nil
+		}
+	}
+	return openapi.SecurityScheme{}, false, nil
+}
+
+// applyScheme maps a security scheme onto the profile's auth strategy or
+// default headers and describes the result.
+func applyScheme(pf *cfgstore.Profile, s openapi.SecurityScheme, token, user, pass, apiKey string) (string, error) {
+	switch {
+	case s.Type == "http" && s.Scheme == "basic":
+		pf.AuthType = "basic"
+		if user != "" {
+			pf.User, pf.Pass = user, pass
+		}
+		return fmt.Sprintf("basic (scheme %s)%s", s.Name, missingNote(pf.User == "", "--user/--pass")), nil
+	case s.Type == "http" && s.Scheme == "bearer", s.Type == "oauth2", s.Type == "openIdConnect":
+		pf.AuthType = "bearer"
+		if token != "" {
+			pf.Token = token
+		}
+		return fmt.Sprintf("bearer (scheme %s)%s", s.Name, missingNote(pf.Token == "", "--token")), nil
+	case s.Type == "apiKey" && s.In == "header":
+		pf.AuthType = "none"
+		if apiKey != "" {
+			pf.Headers[s.Param] = apiKey
+		}
+		return fmt.Sprintf("API key in header %s (scheme %s)%s", s.Param, s.Name, missingNote(pf.Headers[s.Param] == "", "--api-key")), nil
+	case s.Type == "apiKey" && s.In == "cookie":
+		pf.AuthType = "none"
+		if apiKey != "" {
+			pf.Headers["Cookie"] = s.Param + "=" + apiKey
+		}
+		return fmt.Sprintf("API key in cookie %s (scheme %s)%s", s.Param, s.Name, missingNote(apiKey == "", "--api-key")), nil
+	case s.Type == "apiKey" && s.In == "query":
+		return fmt.Sprintf("none – scheme %s sends the key as query parameter %q; add %s==KEY to each call", s.Name, s.Param, s.Param), nil
+	}
+	return "", fmt.Errorf("security scheme %s (%s %s) is not supported; use --scheme to pick another", s.Name, s.Type, s.Scheme)
+}
+
+func missingNote(missing bool, flags string) string {
+	if missing {
+		return " – credentials not set, pass " + flags
+	}
+	return ""
+}
+
+func containsFold(list []string, s string) bool {
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,135 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/jsonpath"
+	"go-rest-api-cli-demo/internal/paginate"
+)
+
+// pageOptions holds the --paginate settings of a call.
+type pageOptions struct {
+	strategy  paginate.Strategy
+	itemsPath string // JSON path of the items array ("" = the body itself)
+	maxPages  int
+	// emit, when set, receives every item as soon as its page arrives
+	// (NDJSON streaming) instead of collecting them into one array.
+	emit func(item interface{}) error
+}
+
+// fetchPages follows pages until the strategy has no next page or maxPages
+// is reached. It returns the last response (body already consumed) and all
+// items as one JSON array, or the body of the last page when opts.emit is
+// set. A page with an HTTP error status ends the loop and is returned as the
+// response with its own body, so the caller's status checks apply to it;
+// transport failures are ExitErrors with exitTransport.
+func (c *CallCommand) fetchPages(cfg httpclient.Config, opts pageOptions, retries int, retryDelay time.Duration) (*http.Response, []byte, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse url: %w", err)
+	}
+	opts.strategy.Prepare(u)
+	cfg.URL = u.String()
+
+	var (
+		all      = []interface{}{}
+		total    int
+		last     *http.Response
+		lastBody []byte
+		page     int
+	)
+	for page = 1; ; page++ {
+		resp, err := sendWithRetry(c.Factory, cfg, retries, retryDelay)
+		var failed *statusError
+		if errors.As(err, &failed) {
+			resp, err = failed.resp, nil
+		}
+		if err != nil {
+			return nil, nil, &ExitError{Code: exitTransport, Err: fmt.Errorf("page %d: %w", page, err)}
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %d: read response: %w", page, err)
+		}
+		last, lastBody = resp, body
+
+		if resp.StatusCode >= 400 {
+			fmt.Fprintf(os.Stderr, "Page %d (%s) failed with HTTP %s\n", page, cfg.URL, resp.Status)
+			return resp, body, nil
+		}
+
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, nil, fmt.Errorf("page %d: response is not JSON: %w", page, err)
+		}
+		items, err := pageItems(doc, opts.itemsPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		total += len(items)
+
+		if opts.emit != nil {
+			for _, it := range items {
+				if err := opts.emit(it); err != nil {
+					return nil, nil, err
+				}
+			}
+		} else {
+			all = append(all, items...)
+		}
+
+		current, _ := url.Parse(cfg.URL)
+		next, err := opts.strategy.Next(current, resp.Header, doc, len(items))
+		if err != nil {
+			return nil, nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		if next == "" {
+			break
+		}
+		if page >= opts.maxPages {
+			fmt.Fprintf(os.Stderr, "Stopped after --max-pages %d (more pages available)\n", opts.maxPages)
+			break
+		}
+		cfg.URL = next
+	}
+
+	fmt.Fprintf(os.Stderr, "Fetched %d item(s) from %d page(s)\n", total, page)
+
+	if opts.emit != nil {
+		return last, lastBody, nil
+	}
+	data, err := json.Marshal(all)
+	if err != nil {
+		return nil, nil, err
+	}
+	return last, data, nil
+}
+
+// pageItems returns the items array of one page.
+func pageItems(doc interface{}, path string) ([]interface{}, error) {
+	v := doc
+	if path != "" && path != "$" {
+		got, err := jsonpath.Get(doc, path)
+		if err != nil {
+			return nil, fmt.Errorf("--items-path: %w", err)
+		}
+		v = got
+	}
+	if v == nil {
+		return nil, nil
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("items at %q are not an array (set --items-path)", path)
+	}
+	return items, nil
+}
@@ -0,0 +1,278 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	cfgstore "go-rest-api-cli-demo/internal/config"
+)
+
+// RequestCommand manages saved named requests (save/list/show/delete/run).
+type RequestCommand struct {
+	call *CallCommand
+}
+
+func NewRequestCommand(call *CallCommand) *RequestCommand {
+	return &RequestCommand{call: call}
+}
+
+func (r *RequestCommand) Name() string { return "request" }
+func (r *RequestCommand) Description() string {
+	return "Manage and run saved requests (save/list/show/delete/run)"
+}
+
+func (r *RequestCommand) Run(args []string) error {
+	if len(args) == 0 {
+		r.printUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "save":
+		return r.runSave(args[1:])
+	case "list":
+		return r.runList()
+	case "show":
+		return r.runShow(args[1:])
+	case "delete":
+		return r.runDelete(args[1:])
+	case "run":
+		return r.runRun(args[1:])
+	default:
+		r.printUsage()
+		return fmt.Errorf("unknown request action: %s", args[0])
+	}
+}
+
+func (r *RequestCommand) printUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  go-rest-api-cli-demo request save --name NAME --url PATH [--method M] [--profile P] [--header ...] [--data JSON | --json-file FILE]")
+	fmt.Println("  go-rest-api-cli-demo request list")
+	fmt.Println("  go-rest-api-cli-demo request show --name NAME")
+	fmt.Println("  go-rest-api-cli-demo request delete --name NAME")
+	fmt.Println("  go-rest-api-cli-demo request run --name NAME [--var key=value ...] [call flags ...]")
+}
+
+func (r *RequestCommand) runSave(args []string) error {
+	fs := flag.NewFlagSet("request save", flag.ContinueOnError)
+
+	name := fs.String("name", "", "Request name (required)")
+	method := fs.String("method", "GET", "HTTP method")
+	path := fs.String("url", "", "URL or path (relative to the profile base URL)")
+	profile := fs.String("profile", "", "Profile to run the request with")
+	inlineJSON := fs.String("data", "", "JSON body template")
+	jsonFilePath := fs.String("json-file", "", "File containing the JSON body template")
+
+	headers := HeaderFlag{}
+	fs.Var(&headers, "header", "Header 'Key: Value' (can be repeated)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("--name is required")
+	}
+	if *path == "" {
+		return fmt.Errorf("--url is required")
+	}
+	if *inlineJSON != "" && *jsonFilePath != "" {
+		return fmt.Errorf("use either --data or --json-file, not both")
+	}
+
+	body := *inlineJSON
+	if *jsonFilePath != "" {
+		data, err := os.ReadFile(*jsonFilePath)
+		if err != nil {
+			return fmt.Errorf("reading json-file: %w", err)
+		}
+		body = string(data)
+	}
+
+	cfg, err := cfgstore.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if *profile != "" {
+		if _, ok := cfg.Profiles[*profile]; !ok {
+			return fmt.Errorf("profile %q not found", *profile)
+		}
+	}
+
+	req := cfgstore.Request{
+		Name:    *name,
+		Method:  strings.ToUpper(*method),
+		Path:    *path,
+		Profile: *profile,
+		Headers: map[string]string(headers),
+		Body:    body,
+	}
+	cfg.Requests[req.Name] = req
+
+	if err := cfgstore.Save(cfg); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+
+	fmt.Printf("Request %q saved\n", req.Name)
+	return nil
+}
+
+func (r *RequestCommand) runList() error {
+	cfg, err := cfgstore.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	if len(cfg.Requests) == 0 {
+		fmt.Println("No requests saved.")
+		return nil
+	}
+
+	names := make([]string, 0, len(cfg.Requests))
+	for name := range cfg.Requests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Requests:")
+	for _, name := range names {
+		req := cfg.Requests[name]
+		profile := req.Profile
+		if profile == "" {
+			profile = "none"
+		}
+		fmt.Printf("- %s (%s %s, profile: %s)\n", name, req.Method, req.Path, profile)
+	}
+	return nil
+}
+
+func (r *RequestCommand) runShow(args []string) error {
+	_, req, err := r.lookup("request show", args)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Request %q\n", req.Name)
+	fmt.Printf("  Method  : %s\n", req.Method)
+	fmt.Printf("  Path    : %s\n", req.Path)
+	if req.Profile != "" {
+		fmt.Printf("  Profile : %s\n", req.Profile)
+	}
+	if len(req.Headers) > 0 {
+		fmt.Println("  Headers :")
+		for _, k := range sortedKeys(req.Headers) {
+			fmt.Printf("    %s: %s\n", k, req.Headers[k])
+		}
+	}
+	if req.Body != "" {
+		fmt.Println("  Body    :")
+		fmt.Println(req.Body)
+	}
+	return nil
+}
+
+func (r *RequestCommand) runDelete(args []string) error {
+	cfg, req, err := r.lookup("request delete", args)
+	if err != nil {
+		return err
+	}
+
+	delete(cfg.Requests, req.Name)
+
+	if err := cfgstore.Save(cfg); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+
+	fmt.Printf("Request %q deleted\n", req.Name)
+	return nil
+}
+
+// runRun hands the saved request to the call command. Extra flags are
+// passed through after the saved values, so they override them (e.g.
+// --profile, --header, --data, --pretty), and --var values fill the
+// {{name}} placeholders like they do for call.
+func (r *RequestCommand) runRun(args []string) error {
+	own, rest := splitOwnFlags(args, "name")
+	_, req, err := r.lookup("request run", own)
+	if err != nil {
+		return err
+	}
+
+	callArgs := []string{"--method", req.Method, "--url", req.Path}
+	if req.Profile != "" {
+		callArgs = append(callArgs, "--profile", req.Profile)
+	}
+	for _, k := range sortedKeys(req.Headers) {
+		callArgs = append(callArgs, "--header", k+": "+req.Headers[k])
+	}
+	if req.Body != "" {
+		callArgs = append(callArgs, "--data", req.Body)
+	}
+
+	if err := r.call.Run(append(callArgs, rest...)); err != nil {
+		return fmt.Errorf("request %q: %w", req.Name, err)
+	}
+	return nil
+}
+
+// lookup parses --name from args and returns the loaded config together
+// with the saved request of that name.
+func (r *RequestCommand) lookup(fsName string, args []string) (*cfgstore.Config, cfgstore.Request, error) {
+	fs := flag.NewFlagSet(fsName, flag.ContinueOnError)
+	name := fs.String("name", "", "Request name")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, cfgstore.Request{}, err
+	}
+
+	if *name == "" {
+		return nil, cfgstore.Request{}, fmt.Errorf("--name is required")
+	}
+
+	cfg, err := cfgstore.Load()
+	if err != nil {
+		return nil, cfgstore.Request{}, fmt.Errorf("load config: %w", err)
+	}
+
+	req, ok := cfg.Requests[*name]
+	if !ok {
+		return nil, cfgstore.Request{}, fmt.Errorf("request %q not found", *name)
+	}
+	return cfg, req, nil
+}
+
+// splitOwnFlags separates the named value flags (in "--name value" or
+// "--name=value" form) from the rest of args, which keep their order.
+func splitOwnFlags(args []string, names ...string) (own, rest []string) {
+	isOwn := func(arg string) (bool, bool) {
+		if !strings.HasPrefix(arg, "-") {
+			return false, false
+		}
+		trimmed := strings.TrimLeft(arg, "-")
+		key, _, hasValue := strings.Cut(trimmed, "=")
+		for _, n := range names {
+			if key == n {
+				return true, hasValue
+			}
+		}
+		return false, false
+	}
+
+	for i := 0; i < len(args); i++ {
+		ok, inline := isOwn(args[i])
+		if !ok {
+			rest = append(rest, args[i])
+			continue
+		}
+		own = append(own, args[i])
+		if !inline && i+1 < len(args) {
+			i++
+			own = append(own, args[i])
+		}
+	}
+	return own, rest
+}
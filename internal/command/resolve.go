@@ -0,0 +1,192 @@
+package command
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-rest-api-cli-demo/internal/auth"
+	cfgstore "go-rest-api-cli-demo/internal/config"
+	"go-rest-api-cli-demo/internal/httpclient"
+)
+
+// Value sources reported by dry-run.
+const (
+	sourceDefault = "default"
+	sourceAuth    = "auth"
+)
+
+// requestInput holds the unresolved pieces of a request as given on the
+// command line, plus the optional profile that provides defaults.
+type requestInput struct {
+	Method   string
+	URL      string
+	Profile  string
+	Headers  map[string]string
+	Body     []byte
+	BodyType string // Content-Type used for Body when none is set (default JSON)
+	AuthType string
+	User     string
+	Pass     string
+	Token    string
+	Timeout  time.Duration
+	Insecure bool
+}
+
+// resolvedRequest is a request after profile defaults and CLI overrides have
+// been applied, together with where each header and auth value came from.
+type resolvedRequest struct {
+	Config httpclient.Config
+
+	URLSource     string
+	HeaderSources map[string]string // canonical header key -> source
+	AuthType      string
+	AuthSources   map[string]string // type|user|pass|token -> source
+
+	// BodySummary describes a streamed body for previews (Config.Body is
+	// empty then).
+	BodySummary string
+}
+
+// resolveRequest applies the precedence rules shared by every command that
+// sends requests: profile values first, then CLI overrides.
+func resolveRequest(in requestInput) (*resolvedRequest, error) {
+	var (
+		pf      cfgstore.Profile
+		profile = in.Profile != ""
+	)
+	if profile {
+		cfg, err := cfgstore.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+		p, ok := cfg.Profiles[in.Profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q not found", in.Profile)
+		}
+		pf = p
+	}
+
+	res := &resolvedRequest{
+		URLSource:     "--url",
+		HeaderSources: make(map[string]string),
+		AuthSources:   make(map[string]string),
+	}
+
+	// Effective URL (profile base URL + relative path)
+	finalURL := in.URL
+	if pf.BaseURL != "" &&
+		!strings.HasPrefix(strings.ToLower(in.URL), "http://") &&
+		!strings.HasPrefix(strings.ToLower(in.URL), "https://") {
+		finalURL = strings.TrimRight(pf.BaseURL, "/") + "/" + strings.TrimLeft(in.URL, "/")
+		res.URLSource = fmt.Sprintf("profile %q base-url + --url", in.Profile)
+	}
+
+	// Merge headers: profile headers first, then CLI overrides. Keys are
+	// canonicalized so "x-env" and "X-Env" collapse into one entry.
+	headers := make(map[string]string)
+	for k, v := range pf.Headers {
+		key := http.CanonicalHeaderKey(k)
+		headers[key] = v
+		res.HeaderSources[key] = fmt.Sprintf("profile %q", in.Profile)
+	}
+	for k, v := range in.Headers {
+		key := http.CanonicalHeaderKey(k)
+		if _, ok := headers[key]; ok {
+			res.HeaderSources[key] = fmt.Sprintf("--header (overrides profile %q)", in.Profile)
+		} else {
+			res.HeaderSources[key] = "--header"
+		}
+		headers[key] = v
+	}
+
+	// Ensure Content-Type if not set
+	if len(in.Body) > 0 {
+		if _, ok := headers["Content-Type"]; !ok {
+			if in.BodyType != "" {
+				headers["Content-Type"] = in.BodyType
+				res.HeaderSources["Content-Type"] = sourceDefault + " (body)"
+			} else {
+				headers["Content-Type"] = "application/json"
+				res.HeaderSources["Content-Type"] = sourceDefault + " (JSON body)"
+			}
+		}
+	}
+
+	// Choose auth strategy (profile defaults + CLI overrides)
+	authType := strings.ToLower(in.AuthType)
+	user, pass, token := in.User, in.Pass, in.Token
+	res.AuthSources["type"] = cliSource(authType != "" && authType != "none", "--auth")
+	res.AuthSources["user"] = cliSource(user != "", "--user")
+	res.AuthSources["pass"] = cliSource(pass != "", "--pass")
+	res.AuthSources["token"] = cliSource(token != "", "--token")
+
+	// Use profile defaults if CLI didn't override
+	if profile {
+		profileAuthType := strings.ToLower(pf.AuthType)
+		if (authType == "" || authType == "none") && profileAuthType != "" && profileAuthType != "none" {
+			authType = profileAuthType
+			res.AuthSources["type"] = fmt.Sprintf("profile %q", in.Profile)
+		}
+		if user == "" && pf.User != "" {
+			user = pf.User
+			res.AuthSources["user"] = fmt.Sprintf("profile %q", in.Profile)
+		}
+		if pass == "" && pf.Pass != "" {
+			pass = pf.Pass
+			res.AuthSources["pass"] = fmt.Sprintf("profile %q", in.Profile)
+		}
+		if token == "" && pf.Token != "" {
+			token = pf.Token
+			res.AuthSources["token"] = fmt.Sprintf("profile %q", in.Profile)
+		}
+	}
+
+	var authStrategy auth.Strategy = auth.NoAuth{}
+	switch authType {
+	case "basic":
+		authStrategy = auth.Basic{User: user, Pass: pass}
+	case "bearer":
+		authStrategy = auth.Bearer{Token: token}
+	case "", "none":
+		// default no auth
+		authType = "none"
+	default:
+		return nil, fmt.Errorf("unknown auth type: %s", authType)
+	}
+	res.AuthType = authType
+
+	res.Config = httpclient.Config{
+		Method:        strings.ToUpper(in.Method),
+		URL:           finalURL,
+		Headers:       headers,
+		Body:          in.Body,
+		Timeout:       in.Timeout,
+		Auth:          authStrategy,
+		SkipTLSVerify: in.Insecure,
+	}
+	return res, nil
+}
+
+// headerSource reports where a header on the built request came from.
+// Headers that were not in the merged header map were set by the auth
+// strategy (e.g. Authorization).
+func (r *resolvedRequest) headerSource(key string, req *http.Request) string {
+	src, ok := r.HeaderSources[key]
+	if ok && req.Header.Get(key) == r.Config.Headers[key] {
+		return src
+	}
+	if ok {
+		return fmt.Sprintf("%s %s (overrides %s)", sourceAuth, r.AuthType, src)
+	}
+	return fmt.Sprintf("%s %s", sourceAuth, r.AuthType)
+}
+
+// cliSource names the flag a value came from, or "default" when it was not set.
+func cliSource(set bool, flagName string) string {
+	if set {
+		return flagName
+	}
+	return sourceDefault
+}
@@ -0,0 +1,377 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"go-rest-api-cli-demo/internal/filter"
+	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/jsonrpc"
+)
+
+// Exit codes for JSON-RPC error objects (see rpcExitCode).
+const (
+	exitRPCParseError     = 10
+	exitRPCInvalidRequest = 11
+	exitRPCMethodNotFound = 12
+	exitRPCInvalidParams  = 13
+	exitRPCInternalError  = 14
+	exitRPCServerError    = 15
+	exitRPCAppError       = 16
+)
+
+// RPCCommand = "rpc" subcommand: JSON-RPC 2.0 calls and batches.
+type RPCCommand struct {
+	Factory httpclient.Factory
+}
+
+func NewRPCCommand(factory httpclient.Factory) *RPCCommand {
+	return &RPCCommand{Factory: factory}
+}
+
+func (r *RPCCommand) Name() string        { return "rpc" }
+func (r *RPCCommand) Description() string { return "Send JSON-RPC 2.0 calls (single or batch)" }
+
+// rpcSpec is one call of a --batch file.
+type rpcSpec struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Notify bool            `json:"notify"`
+}
+
+func (r *RPCCommand) Run(args []string) error {
+	// "rpc METHOD [flags] [PARAM ...]"
+	var methodName string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		methodName, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("rpc", flag.ContinueOnError)
+
+	var (
+		urlStr      = fs.String("url", "", "JSON-RPC endpoint (relative with --profile, default the base URL)")
+		profileName = fs.String("profile", "", "Profile name to use from config")
+		paramsJSON  = fs.String("params", "", "Params as a JSON array or object")
+		batchFile   = fs.String("batch", "", "File with calls: JSON array or JSONL of {method, params, notify} ('-' = stdin)")
+		notify      = fs.Bool("notify", false, "Send as a notification (no id, no response expected)")
+		firstID     = fs.Int64("id", 1, "Id of the first call; batch calls count up from it")
+
+		timeoutSec = fs.Int("timeout", 30, "Timeout in seconds")
+		insecure   = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
+		retries    = fs.Int("retries", 0, "Number of retries on failure (network/5xx)")
+		retryWait  = fs.Int("retry-delay", 1, "Delay between retries in seconds")
+
+		authType = fs.String("auth", "none", "Auth: none|basic|bearer")
+		user     = fs.String("user", "", "Username for basic auth")
+		pass     = fs.String("pass", "", "Password for basic auth")
+		token    = fs.String("token", "", "Bearer token")
+
+		pretty    = fs.Bool("pretty", false, "Pretty-print JSON output")
+		full      = fs.Bool("full", false, "Print the whole response envelope(s), not only the result")
+		filterStr = fs.String("filter", "", "JSONPath or jq-style expression applied to the printed JSON")
+		raw       = fs.Bool("raw", false, "With --filter, print strings without quotes")
+		outPath   = fs.String("out", "", "Write the printed result to file")
+	)
+
+	headers := HeaderFlag{}
+	fs.Var(&headers, "header", "HTTP header 'Key: Value' (can be repeated)")
+	named := VarFlag{}
+	fs.Var(&named, "named", "Named param 'name=value'; JSON values are typed (can be repeated)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	endpoint := *urlStr
+	if endpoint == "" && *profileName == "" {
+		return fmt.Errorf("--url is required")
+	}
+
+	// Build the calls
+	id := *firstID
+	nextID := func(notification bool) *int64 {
+		if notification {
+			return nil
+		}
+		v := id
+		id++
+		return &v
+	}
+
+	var calls []jsonrpc.Request
+	if *batchFile != "" {
+		if methodName != "" || *paramsJSON != "" || fs.NArg() > 0 || len(named) > 0 {
+			return fmt.Errorf("--batch cannot be combined with a method or params")
+		}
+		specs, err := readRPCBatch(*batchFile)
+		if err != nil {
+			return err
+		}
+		for i, s := range specs {
+			call, err := jsonrpc.NewRequest(s.Method, s.Params, nextID(s.Notify))
+			if err != nil {
+				return fmt.Errorf("batch call %d: %w", i+1, err)
+			}
+			calls = append(calls, call)
+		}
+		if len(calls) == 0 {
+			return fmt.Errorf("%s: no calls found", *batchFile)
+		}
+	} else {
+		if methodName == "" {
+			return fmt.Errorf("a method name is required (rpc METHOD [PARAM ...] or --batch FILE)")
+		}
+		params, err := rpcParams(*paramsJSON, fs.Args(), named)
+		if err != nil {
+			return err
+		}
+		call, err := jsonrpc.NewRequest(methodName, params, nextID(*notify))
+		if err != nil {
+			return err
+		}
+		calls = append(calls, call)
+	}
+
+	var payload interface{} = calls
+	if *batchFile == "" {
+		payload = calls[0]
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling request: %w", err)
+	}
+
+	resolved, err := resolveRequest(requestInput{
+		Method:   "POST",
+		URL:      endpoint,
+		Profile:  *profileName,
+		Headers:  headers,
+		Body:     body,
+		AuthType: *authType,
+		User:     *user,
+		Pass:     *pass,
+		Token:    *token,
+		Timeout:  time.Duration(*timeoutSec) * time.Second,
+		Insecure: *insecure,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := sendWithRetry(r.Factory, resolved.Config, *retries, time.Duration(*retryWait)*time.Second)
+	var status5xx *statusError
+	if errors.As(err, &status5xx) {
+		resp, err = status5xx.resp, nil // servers may answer errors with a 5xx envelope
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	responses, err := jsonrpc.DecodeResponses(respBody)
+	if err != nil {
+		if resp.StatusCode >= 400 {
+			return &ExitError{Code: exitHTTPStatus, Err: fmt.Errorf("HTTP %s: %s", resp.Status, strings.TrimSpace(string(respBody)))}
+		}
+		return err
+	}
+	if len(responses) == 0 {
+		if resp.StatusCode >= 400 {
+			return &ExitError{Code: exitHTTPStatus, Err: fmt.Errorf("HTTP %s", resp.Status)}
+		}
+		fmt.Fprintln(os.Stderr, "No response (notification)")
+		return nil
+	}
+
+	// Pair responses with calls by id; batch answers may come in any order
+	byID := make(map[string]int, len(responses))
+	for i, res := range responses {
+		byID[string(bytes.TrimSpace(res.ID))] = i
+	}
+	used := make(map[int]bool, len(responses))
+	var (
+		ordered []jsonrpc.Response
+		labels  []string
+	)
+	for _, c := range calls {
+		if c.ID == nil {
+			continue
+		}
+		if i, ok := byID[fmt.Sprint(*c.ID)]; ok && !used[i] {
+			used[i] = true
+			ordered = append(ordered, responses[i])
+			labels = append(labels, fmt.Sprintf("%s (id %d)", c.Method, *c.ID))
+		}
+	}
+	// Answers the server could not attribute to a call (e.g. id null)
+	for i, res := range responses {
+		if !used[i] {
+			ordered = append(ordered, res)
+			labels = append(labels, "id "+string(res.ID))
+		}
+	}
+
+	// Print results (or envelopes)
+	var out []byte
+	switch {
+	case *batchFile != "":
+		out, err = json.Marshal(ordered)
+	case *full:
+		out, err = json.Marshal(ordered[0])
+	case ordered[0].Error == nil:
+		out = ordered[0].Result
+	}
+	if err != nil {
+		return err
+	}
+	if len(out) > 0 {
+		if *filterStr != "" {
+			f, err := filter.Compile(*filterStr)
+			if err != nil {
+				return fmt.Errorf("--filter: %w", err)
+			}
+			if out, err = applyFilter(f, out, *pretty, *raw); err != nil {
+				return err
+			}
+		} else if *pretty {
+			var buf bytes.Buffer
+			if err := json.Indent(&buf, out, "", "  "); err == nil {
+				out = buf.Bytes()
+			}
+		}
+		fmt.Println(string(out))
+		if *outPath != "" {
+			if err := os.WriteFile(*outPath, out, 0o644); err != nil {
+				return fmt.Errorf("failed to write response to file: %w", err)
+			}
+		}
+	}
+
+	// Error objects: readable messages on stderr, mapped to the exit code
+	var (
+		failed   int
+		firstErr *jsonrpc.Error
+	)
+	for i, res := range ordered {
+		if res.Error == nil {
+			continue
+		}
+		failed++
+		if firstErr == nil {
+			firstErr = res.Error
+		}
+		if *batchFile != "" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", labels[i], res.Error)
+		}
+	}
+	if firstErr != nil {
+		err := fmt.Errorf("rpc: %d of %d call(s) failed", failed, len(ordered))
+		if *batchFile == "" {
+			err = fmt.Errorf("rpc: %w", firstErr)
+		}
+		return &ExitError{Code: rpcExitCode(firstErr.Code), Err: err}
+	}
+	return nil
+}
+
+// rpcParams builds params from --params, positional values or --named.
+// Positional values and --named values that are valid JSON keep their type.
+func rpcParams(inline string, positional []string, named VarFlag) (json.RawMessage, error) {
+	sources := 0
+	for _, used := range []bool{inline != "", len(positional) > 0, len(named) > 0} {
+		if used {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return nil, fmt.Errorf("use only one of --params, positional params or --named")
+	}
+
+	switch {
+	case inline != "":
+		if !json.Valid([]byte(inline)) {
+			return nil, fmt.Errorf("--params is not valid JSON")
+		}
+		return json.RawMessage(inline), nil
+	case len(positional) > 0:
+		list := make([]interface{}, len(positional))
+		for i, p := range positional {
+			list[i] = typedValue(p)
+		}
+		return json.Marshal(list)
+	case len(named) > 0:
+		obj := make(map[string]interface{}, len(named))
+		for k, v := range named {
+			obj[k] = typedValue(v)
+		}
+		return json.Marshal(obj)
+	}
+	return nil, nil
+}
+
+// typedValue returns s decoded as JSON when possible, else s as a string.
+func typedValue(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err == nil {
+		return v
+	}
+	return s
+}
+
+// readRPCBatch reads calls from a JSON array or JSONL file.
+func readRPCBatch(path string) ([]rpcSpec, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open batch file: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var specs []rpcSpec
+	_, err := decodeJSONStream(in, true, func(v json.RawMessage) error {
+		var s rpcSpec
+		if err := json.Unmarshal(v, &s); err != nil {
+			return fmt.Errorf("batch call %d: %w", len(specs)+1, err)
+		}
+		specs = append(specs, s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return specs, nil
+}
+
+// rpcExitCode maps a JSON-RPC error code to the process exit status.
+func rpcExitCode(code int) int {
+	switch {
+	case code == jsonrpc.CodeParseError:
+		return exitRPCParseError
+	case code == jsonrpc.CodeInvalidRequest:
+		return exitRPCInvalidRequest
+	case code == jsonrpc.CodeMethodNotFound:
+		return exitRPCMethodNotFound
+	case code == jsonrpc.CodeInvalidParams:
+		return exitRPCInvalidParams
+	case code == jsonrpc.CodeInternalError:
+		return exitRPCInternalError
+	case code <= -32000 && code >= -32099:
+		return exitRPCServerError
+	}
+	return exitRPCAppError
+}
@@ -0,0 +1,318 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/httpfile"
+	"go-rest-api-cli-demo/internal/jsonpath"
+	"go-rest-api-cli-demo/internal/output"
+	"go-rest-api-cli-demo/internal/vars"
+)
+
+// RunCommand executes the requests of a .http/.rest file.
+type RunCommand struct {
+	Factory httpclient.Factory
+}
+
+func NewRunCommand(factory httpclient.Factory) *RunCommand {
+	return &RunCommand{Factory: factory}
+}
+
+func (r *RunCommand) Name() string        { return "run" }
+func (r *RunCommand) Description() string { return "Run requests from a .http/.rest file" }
+
+// namedResponse is what later requests can reference as
+// {{name.response.body.<path>}} / {{name.response.headers.<Header>}}.
+type namedResponse struct {
+	header http.Header
+	body   []byte
+}
+
+func (r *RunCommand) Run(args []string) error {
+	// Allow "run FILE [flags]" as well as "run --file FILE [flags]"
+	var positional string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		positional, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+
+	var (
+		filePath     = fs.String("file", positional, "Path to the .http/.rest file")
+		only         = fs.String("name", "", "Only run the request(s) with this @name (comma-separated)")
+		profileName  = fs.String("profile", "", "Profile for relative URLs, default headers and auth")
+		timeoutSec   = fs.Int("timeout", 30, "Timeout in seconds (per request)")
+		insecure     = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
+		pretty       = fs.Bool("pretty", false, "Pretty-print JSON responses")
+		failOnStatus = fs.Bool("fail", false, "Treat HTTP 4xx/5xx responses as failures")
+		bail         = fs.Bool("bail", false, "Stop at the first failed request")
+	)
+
+	values := VarFlag{}
+	fs.Var(&values, "var", "Variable 'name=value', overrides @name in the file (can be repeated)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *filePath == "" {
+		return fmt.Errorf("a .http file is required (run FILE or --file FILE)")
+	}
+
+	hf, err := httpfile.ParseFile(*filePath)
+	if err != nil {
+		return err
+	}
+	if len(hf.Requests) == 0 {
+		return fmt.Errorf("%s: no requests found", *filePath)
+	}
+
+	selected := map[string]bool{}
+	for _, n := range strings.Split(*only, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			selected[n] = true
+		}
+	}
+
+	responses := map[string]namedResponse{}
+	lookup := r.lookupFunc(hf, values, responses)
+
+	var ran, failed int
+	for i, hreq := range hf.Requests {
+		if len(selected) > 0 && !selected[hreq.Name] {
+			continue
+		}
+		ran++
+
+		label := hreq.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+
+		status, resp, err := r.runOne(hreq, lookup, requestInput{
+			Profile:  *profileName,
+			Timeout:  time.Duration(*timeoutSec) * time.Second,
+			Insecure: *insecure,
+		}, label, *pretty)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s (line %d): %v\n", label, hreq.Line, err)
+			failed++
+		} else {
+			if hreq.Name != "" {
+				responses[hreq.Name] = resp
+			}
+			if *failOnStatus && status >= 400 {
+				failed++
+			}
+		}
+
+		if failed > 0 && *bail {
+			break
+		}
+	}
+
+	if ran == 0 {
+		return fmt.Errorf("no request matched --name %q", *only)
+	}
+
+	fmt.Printf("\n%d request(s) run, %d failed\n", ran, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d request(s) failed", failed, ran)
+	}
+	return nil
+}
+
+// runOne expands, sends and prints a single request.
+func (r *RunCommand) runOne(hreq httpfile.Request, lookup vars.LookupFunc, base requestInput, label string, pretty bool) (int, namedResponse, error) {
+	urlStr, err := vars.ExpandFunc(hreq.URL, lookup)
+	if err != nil {
+		return 0, namedResponse{}, fmt.Errorf("url: %w", err)
+	}
+
+	headers := map[string]string{}
+	for _, h := range hreq.Headers {
+		v, err := vars.ExpandFunc(h.Value, lookup)
+		if err != nil {
+			return 0, namedResponse{}, fmt.Errorf("header %s: %w", h.Key, err)
+		}
+		headers[h.Key] = v
+	}
+
+	body, err := vars.ExpandFunc(hreq.Body, lookup)
+	if err != nil {
+		return 0, namedResponse{}, fmt.Errorf("body: %w", err)
+	}
+
+	in := base
+	in.Method = hreq.Method
+	in.URL = urlStr
+	in.Headers = headers
+	in.Body = []byte(body)
+	if body != "" {
+		in.BodyType = sniffContentType(in.Body)
+	}
+
+	resolved, err := resolveRequest(in)
+	if err != nil {
+		return 0, namedResponse{}, err
+	}
+
+	req, client, err := r.Factory.Build(resolved.Config)
+	if err != nil {
+		return 0, namedResponse{}, fmt.Errorf("build request: %w", err)
+	}
+
+	fmt.Printf("\n=== %s: %s %s ===\n", label, req.Method, req.URL.String())
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, namedResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, namedResponse{}, fmt.Errorf("read response: %w", err)
+	}
+	elapsed := time.Since(start)
+
+	bodyToPrint := respBody
+	if pretty && strings.HasPrefix(strings.ToLower(resp.Header.Get("Content-Type")), "application/json") {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, respBody, "", "  "); err == nil {
+			bodyToPrint = buf.Bytes()
+		}
+	}
+
+	fmt.Printf("Status: %s (%d ms)\n", resp.Status, elapsed.Milliseconds())
+	for _, k := range output.SortedHeaderKeys(resp.Header) {
+		fmt.Printf("%s: %s\n", k, strings.Join(resp.Header[k], ", "))
+	}
+	fmt.Println()
+	fmt.Println(string(bodyToPrint))
+
+	return resp.StatusCode, namedResponse{header: resp.Header, body: respBody}, nil
+}
+
+// lookupFunc resolves {{...}} expressions: --var values, @file variables
+// (which may reference each other), system variables ($guid, $timestamp,
+// $randomInt MIN MAX, $processEnv NAME, $datetime), responses of earlier
+// named requests and template functions (uuid, fake.email, x | base64...).
+func (r *RunCommand) lookupFunc(hf *httpfile.File, cli map[string]string, responses map[string]namedResponse) vars.LookupFunc {
+	var lookup, resolve vars.LookupFunc
+	depth := 0
+	resolve = func(expr string) (string, bool, error) {
+		if v, ok := cli[expr]; ok {
+			return v, true, nil
+		}
+		if v, ok := hf.Vars[expr]; ok {
+			if depth > 10 {
+				return "", false, fmt.Errorf("variable nesting too deep")
+			}
+			depth++
+			defer func() { depth-- }()
+			out, err := vars.ExpandFunc(v, lookup)
+			return out, err == nil, err
+		}
+		if strings.HasPrefix(expr, "$") {
+			return systemVariable(expr)
+		}
+		if name, rest, ok := strings.Cut(expr, ".response."); ok {
+			resp, ok := responses[name]
+			if !ok {
+				return "", false, nil
+			}
+			return responseValue(resp, rest)
+		}
+		return "", false, nil
+	}
+	lookup = func(expr string) (string, bool, error) {
+		if v, ok, err := resolve(expr); ok || err != nil {
+			return v, ok, err
+		}
+		return vars.Eval(expr, resolve)
+	}
+	return lookup
+}
+
+func systemVariable(expr string) (string, bool, error) {
+	fields := strings.Fields(expr)
+	switch fields[0] {
+	case "$guid", "$uuid":
+		return vars.UUID(), true, nil
+	case "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), true, nil
+	case "$datetime":
+		return time.Now().UTC().Format(time.RFC3339), true, nil
+	case "$randomInt":
+		if len(fields) != 3 {
+			return "", false, fmt.Errorf("usage: $randomInt MIN MAX")
+		}
+		lo, err1 := strconv.ParseInt(fields[1], 10, 64)
+		hi, err2 := strconv.ParseInt(fields[2], 10, 64)
+		if err1 != nil || err2 != nil || hi <= lo {
+			return "", false, fmt.Errorf("usage: $randomInt MIN MAX (MIN < MAX)")
+		}
+		n, err := vars.RandInt(lo, hi-1) // MAX is excluded, as in REST Client
+		if err != nil {
+			return "", false, err
+		}
+		return strconv.FormatInt(n, 10), true, nil
+	case "$processEnv":
+		if len(fields) != 2 {
+			return "", false, fmt.Errorf("usage: $processEnv NAME")
+		}
+		v, ok := os.LookupEnv(fields[1])
+		return v, ok, nil
+	}
+	return "", false, nil
+}
+
+// responseValue reads "body.<path>", "body.*" or "headers.<Header>".
+func responseValue(resp namedResponse, ref string) (string, bool, error) {
+	switch {
+	case ref == "body" || ref == "body.*":
+		return string(resp.body), true, nil
+	case strings.HasPrefix(ref, "body."):
+		var doc interface{}
+		if err := json.Unmarshal(resp.body, &doc); err != nil {
+			return "", false, fmt.Errorf("response body is not JSON: %w", err)
+		}
+		v, err := jsonpath.Get(doc, strings.TrimPrefix(ref, "body."))
+		if err != nil {
+			return "", false, err
+		}
+		return scalarString(v), true, nil
+	case strings.HasPrefix(ref, "headers."):
+		key := strings.TrimPrefix(ref, "headers.")
+		if _, ok := resp.header[http.CanonicalHeaderKey(key)]; !ok {
+			return "", false, nil
+		}
+		return resp.header.Get(key), true, nil
+	}
+	return "", false, fmt.Errorf("unsupported response reference %q", ref)
+}
+
+// scalarString renders a decoded JSON value for substitution into text:
+// strings are used as-is, everything else as compact JSON.
+func scalarString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go-rest-api-cli-demo/internal/jsonschema"
+)
+
+// loadSchema loads the schema file of a --*-schema flag (nil when unset).
+func loadSchema(flagName, path string) (*jsonschema.Schema, error) {
+	if path == "" {
+		return nil, nil
+	}
+	s, err := jsonschema.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", flagName, err)
+	}
+	return s, nil
+}
+
+// checkSchema validates a JSON body against schema. Failures are listed on
+// stderr and returned as an ExitError with exitSchemaInvalid.
+func checkSchema(schema *jsonschema.Schema, schemaPath, what string, body []byte) error {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return &ExitError{Code: exitSchemaInvalid, Err: fmt.Errorf("%s is not JSON, cannot validate against %s: %w", what, schemaPath, err)}
+	}
+	errs, err := schema.Validate(doc)
+	if err != nil {
+		return fmt.Errorf("%s: %w", schemaPath, err)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "%s does not match %s:\n", what, schemaPath)
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "  %s\n", e)
+	}
+	return &ExitError{Code: exitSchemaInvalid, Err: fmt.Errorf("%s does not match %s (%d error(s))", what, schemaPath, len(errs))}
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go-rest-api-cli-demo/internal/httpclient"
+)
+
+// statusError is the error when the last attempt got a 5xx response. It
+// keeps that response, with its body buffered, for callers that show it.
+type statusError struct {
+	resp *http.Response
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("received HTTP %d", e.resp.StatusCode)
+}
+
+// sendWithRetry builds and sends cfg, retrying on network errors and HTTP
+// 5xx responses. The caller must close the returned response body.
+func sendWithRetry(factory httpclient.Factory, cfg httpclient.Config, retries int, delay time.Duration) (*http.Response, error) {
+	attempts := retries + 1
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var (
+		resp    *http.Response
+		lastErr error
+	)
+
+	for i := 0; i < attempts; i++ {
+		req, client, err := factory.Build(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+
+		resp, err = client.Do(req)
+		if err != nil {
+			lastErr = err
+		} else if resp.StatusCode >= 500 && resp.StatusCode <= 599 {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			lastErr = &statusError{resp: resp}
+		} else {
+			return resp, nil
+		}
+
+		if i < attempts-1 {
+			time.Sleep(delay)
+		}
+	}
+
+	return nil, fmt.Errorf("request failed after %d attempt(s): %w", attempts, lastErr)
+}
+
+// readWithTimeout reads body to the end, giving up after timeout (0 = no
+// limit) by closing it.
+func readWithTimeout(body io.ReadCloser, timeout time.Duration) ([]byte, error) {
+	if timeout <= 0 {
+		return io.ReadAll(body)
+	}
+	timer := time.AfterFunc(timeout, func() { body.Close() })
+	data, err := io.ReadAll(body)
+	if !timer.Stop() && err != nil {
+		return nil, fmt.Errorf("timeout after %s", timeout)
+	}
+	return data, err
+}
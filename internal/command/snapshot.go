@@ -0,0 +1,147 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/jsondiff"
+)
+
+// snapshotIgnored replaces masked values in snapshots.
+const snapshotIgnored = "<ignored>"
+
+// volatileHeaders change on every response and are never snapshotted.
+var volatileHeaders = []string{"Date", "Content-Length", "Connection", "Keep-Alive", "Transfer-Encoding", "Age"}
+
+// snapshotOptions says what a snapshot leaves out.
+type snapshotOptions struct {
+	ignorePaths   []*jsondiff.Pattern // in the body, e.g. $.id, $..createdAt
+	ignoreHeaders []string
+	update        bool
+}
+
+func newSnapshotOptions(paths, headers []string, update bool) (snapshotOptions, error) {
+	patterns, err := jsondiff.CompileAll(paths)
+	if err != nil {
+		return snapshotOptions{}, fmt.Errorf("snapshot ignore: %w", err)
+	}
+	return snapshotOptions{ignorePaths: patterns, ignoreHeaders: headers, update: update}, nil
+}
+
+// snapshotFile is the stored form of a response.
+type snapshotFile struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"` // JSON value, or text
+}
+
+// normalize builds the snapshot of a response: status, headers except
+// ignored and volatile ones, and the body (decoded JSON when possible)
+// with ignored paths masked.
+func (o snapshotOptions) normalize(status int, header http.Header, body []byte) snapshotFile {
+	snap := snapshotFile{Status: status, Headers: map[string]string{}}
+	skip := map[string]bool{}
+	for _, h := range append(append([]string(nil), volatileHeaders...), o.ignoreHeaders...) {
+		skip[http.CanonicalHeaderKey(strings.TrimSpace(h))] = true
+	}
+	for k, v := range header {
+		if !skip[http.CanonicalHeaderKey(k)] {
+			snap.Headers[http.CanonicalHeaderKey(k)] = strings.Join(v, ", ")
+		}
+	}
+
+	var doc interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &doc); err == nil {
+			snap.Body = jsondiff.Mask(doc, o.ignorePaths, snapshotIgnored)
+		} else {
+			snap.Body = string(body)
+		}
+	}
+	return snap
+}
+
+// compareSnapshot compares a response with the snapshot at path. A missing
+// snapshot (or update) writes it; written reports that. Otherwise it
+// returns the changes from the stored snapshot, ignore rules applied to
+// both sides so new rules take effect without an update.
+func compareSnapshot(path string, o snapshotOptions, status int, header http.Header, body []byte) (changes []jsondiff.Change, written bool, err error) {
+	current := o.normalize(status, header, body)
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) || (err == nil && o.update):
+		return nil, true, writeSnapshot(path, current)
+	case err != nil:
+		return nil, false, fmt.Errorf("read snapshot: %w", err)
+	}
+
+	var stored snapshotFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, false, fmt.Errorf("snapshot %s: %w", path, err)
+	}
+	if stored.Headers == nil {
+		stored.Headers = map[string]string{}
+	}
+	for _, h := range o.ignoreHeaders {
+		delete(stored.Headers, http.CanonicalHeaderKey(strings.TrimSpace(h)))
+	}
+	stored.Body = jsondiff.Mask(stored.Body, o.ignorePaths, snapshotIgnored)
+
+	return jsondiff.Diff(toJSONValue(stored), toJSONValue(current)), false, nil
+}
+
+func writeSnapshot(path string, snap snapshotFile) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep "<ignored>" readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snap); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("write snapshot: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
+
+// toJSONValue converts a snapshot to plain decoded JSON for diffing.
+func toJSONValue(snap snapshotFile) interface{} {
+	data, _ := json.Marshal(snap)
+	var v interface{}
+	_ = json.Unmarshal(data, &v)
+	return v
+}
+
+// checkSnapshot is compareSnapshot for call: it reports on stderr and
+// fails with exitAssertion when the response differs.
+func checkSnapshot(path string, o snapshotOptions, status int, header http.Header, body []byte) error {
+	changes, written, err := compareSnapshot(path, o, status, header, body)
+	if err != nil {
+		return err
+	}
+	if written {
+		fmt.Fprintf(os.Stderr, "Snapshot written to %s\n", path)
+		return nil
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Response differs from snapshot %s (old -> new):\n", path)
+	for _, c := range changes {
+		fmt.Fprintf(os.Stderr, "  %s\n", c)
+	}
+	return &ExitError{Code: exitAssertion, Err: fmt.Errorf("response differs from snapshot %s in %d place(s); use --update-snapshot to accept it", path, len(changes))}
+}
@@ -0,0 +1,398 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go-rest-api-cli-demo/internal/filter"
+	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/output"
+	"go-rest-api-cli-demo/internal/sse"
+)
+
+// defaultSSERetry is the reconnection delay used until the server sends a
+// retry: field.
+const defaultSSERetry = 3 * time.Second
+
+// sseOptions holds the --sse settings of a call.
+type sseOptions struct {
+	maxEvents  int           // stop after this many events (0 = no limit)
+	untilEvent string        // stop after an event of this type
+	duration   time.Duration // stop after this long (0 = no limit)
+	reconnects int           // reconnect attempts when the stream ends
+
+	jsonLines bool // one JSON object per event (--output json)
+	raw       bool // only the data of each event
+	pretty    bool
+	filter    *filter.Filter
+}
+
+// isEventStream reports whether resp is a text/event-stream response.
+func isEventStream(resp *http.Response) bool {
+	return strings.HasPrefix(strings.ToLower(resp.Header.Get("Content-Type")), "text/event-stream")
+}
+
+// streamSSE prints the events of resp as they arrive and reconnects with
+// Last-Event-ID until a stop condition is met. It closes resp.Body.
+func (c *CallCommand) streamSSE(cfg httpclient.Config, resp *http.Response, w io.Writer, opts sseOptions, retries int, retryDelay time.Duration) error {
+	var (
+		mu      sync.Mutex
+		current = resp.Body
+		expired = make(chan struct{})
+	)
+	if opts.duration > 0 {
+		timer := time.AfterFunc(opts.duration, func() {
+			mu.Lock()
+			defer mu.Unlock()
+			close(expired)
+			current.Close() // unblocks the pending read
+		})
+		defer timer.Stop()
+	}
+	isExpired := func() bool {
+		select {
+		case <-expired:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var (
+		count    int
+		lastID   string
+		reconnIn = defaultSSERetry
+	)
+	for attempt := 0; ; attempt++ {
+		reader := sse.NewReader(current)
+		var streamErr error
+		for {
+			ev, err := reader.Next()
+			if err != nil {
+				streamErr = err
+				break
+			}
+			if ev.Retry > 0 {
+				reconnIn = ev.Retry
+			}
+			if ev.Event == "" {
+				continue // retry-only block, nothing to show
+			}
+			if err := writeEvent(w, ev, opts); err != nil {
+				current.Close()
+				return err
+			}
+			count++
+			if (opts.maxEvents > 0 && count >= opts.maxEvents) || (opts.untilEvent != "" && ev.Event == opts.untilEvent) {
+				current.Close()
+				fmt.Fprintf(os.Stderr, "Received %d event(s)\n", count)
+				return nil
+			}
+		}
+		lastID = reader.LastID()
+		current.Close()
+
+		if isExpired() {
+			fmt.Fprintf(os.Stderr, "Received %d event(s); stopped after --stream-timeout\n", count)
+			return nil
+		}
+		if attempt >= opts.reconnects {
+			fmt.Fprintf(os.Stderr, "Received %d event(s); stream closed\n", count)
+			if errors.Is(streamErr, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read event stream: %w", streamErr)
+		}
+
+		fmt.Fprintf(os.Stderr, "Stream closed; reconnecting in %s (Last-Event-ID: %q)\n", reconnIn, lastID)
+		select {
+		case <-time.After(reconnIn):
+		case <-expired:
+			fmt.Fprintf(os.Stderr, "Received %d event(s); stopped after --stream-timeout\n", count)
+			return nil
+		}
+
+		next, err := reconnectSSE(c.Factory, cfg, lastID, retries, retryDelay)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			fmt.Fprintf(os.Stderr, "Received %d event(s); server ended the stream (204)\n", count)
+			return nil
+		}
+
+		mu.Lock()
+		current = next.Body
+		mu.Unlock()
+		if isExpired() {
+			current.Close()
+			fmt.Fprintf(os.Stderr, "Received %d event(s); stopped after --stream-timeout\n", count)
+			return nil
+		}
+	}
+}
+
+// reconnectSSE resends cfg with Last-Event-ID. A nil response means the
+// server answered 204 No Content, which tells clients not to reconnect.
+func reconnectSSE(factory httpclient.Factory, cfg httpclient.Config, lastID string, retries int, retryDelay time.Duration) (*http.Response, error) {
+	headers := make(map[string]string, len(cfg.Headers)+1)
+	for k, v := range cfg.Headers {
+		headers[k] = v
+	}
+	if lastID != "" {
+		headers["Last-Event-ID"] = lastID
+	}
+	cfg.Headers = headers
+
+	resp, err := sendWithRetry(factory, cfg, retries, retryDelay)
+	if err != nil {
+		return nil, fmt.Errorf("reconnect: %w", err)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		resp.Body.Close()
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK || !isEventStream(resp) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("reconnect: HTTP %s (%s)", resp.Status, resp.Header.Get("Content-Type"))
+	}
+	return resp, nil
+}
+
+// writeEvent prints one event in the selected style. Filters and --pretty
+// apply to the data when it is JSON.
+func writeEvent(w io.Writer, ev *sse.Event, opts sseOptions) error {
+	data := []byte(ev.Data)
+	if json.Valid(data) {
+		var err error
+		if opts.filter != nil {
+			if data, err = applyFilter(opts.filter, data, opts.pretty && !opts.jsonLines, opts.raw); err != nil {
+				return fmt.Errorf("event %q: %w", ev.ID, err)
+			}
+		} else if opts.pretty && !opts.jsonLines {
+			var buf bytes.Buffer
+			if json.Indent(&buf, data, "", "  ") == nil {
+				data = buf.Bytes()
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case opts.jsonLines:
+		line := struct {
+			ID    string      `json:"id,omitempty"`
+			Event string      `json:"event"`
+			Data  interface{} `json:"data"`
+		}{ev.ID, ev.Event, string(data)}
+		if json.Valid(data) {
+			line.Data = json.RawMessage(data)
+		}
+		enc, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		buf.Write(enc)
+		buf.WriteByte('\n')
+	case opts.raw:
+		buf.Write(data)
+		buf.WriteByte('\n')
+	default:
+		// Same layout as the wire format, so it stays easy to read and grep
+		fmt.Fprintf(&buf, "event: %s\n", ev.Event)
+		if ev.ID != "" {
+			fmt.Fprintf(&buf, "id: %s\n", ev.ID)
+		}
+		for _, l := range strings.Split(string(data), "\n") {
+			fmt.Fprintf(&buf, "data: %s\n", l)
+		}
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// streamWriter returns stdout, or stdout plus the --out file, for output
+// that is written while it arrives.
+func streamWriter(outPath string) (io.Writer, func(), error) {
+	if outPath == "" {
+		return os.Stdout, func() {}, nil
+	}
+	f, err := os.Create(outPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to write response to file: %w", err)
+	}
+	return io.MultiWriter(os.Stdout, f), func() { f.Close() }, nil
+}
+
+// handleEventStream prints the response head (text mode) and streams the
+// events of resp to stdout and --out.
+func (c *CallCommand) handleEventStream(cfg httpclient.Config, resp *http.Response, outPath string, textMode bool, opts sseOptions, retries int, retryDelay time.Duration) error {
+	w, closeOut, err := streamWriter(outPath)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	if textMode && !opts.raw {
+		printStreamHead("event stream", resp)
+	}
+	return c.streamSSE(cfg, resp, w, opts, retries, retryDelay)
+}
+
+// jsonStreamTypes are the Content-Types that are always streamed value by
+// value.
+var jsonStreamTypes = []string{
+	"application/x-ndjson",
+	"application/ndjson",
+	"application/jsonl",
+	"application/x-jsonlines",
+	"application/stream+json",
+}
+
+// isJSONStream reports whether resp should be streamed as JSON values:
+// always for NDJSON-style types, and for plain JSON when --stream is set.
+func isJSONStream(resp *http.Response, force bool) bool {
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	for _, t := range jsonStreamTypes {
+		if strings.HasPrefix(ct, t) {
+			return true
+		}
+	}
+	return force && (strings.HasPrefix(ct, "application/json") || strings.Contains(ct, "+json"))
+}
+
+// jsonStreamOptions holds the output settings of a streamed JSON response.
+type jsonStreamOptions struct {
+	splitArray bool // stream the elements of a top-level array
+	jsonLines  bool
+	raw        bool
+	pretty     bool
+	filter     *filter.Filter
+}
+
+// handleJSONStream prints each JSON value of resp as soon as it is decoded,
+// so memory use does not grow with the size of the response.
+func handleJSONStream(resp *http.Response, outPath string, textMode bool, opts jsonStreamOptions) error {
+	w, closeOut, err := streamWriter(outPath)
+	if err != nil {
+		return err
+	}
+	defer closeOut()
+
+	if textMode && !opts.raw {
+		printStreamHead("streamed", resp)
+	}
+
+	pretty := opts.pretty && !opts.jsonLines
+	n, err := decodeJSONStream(resp.Body, opts.splitArray, func(v json.RawMessage) error {
+		return writeJSONItem(w, v, opts.filter, pretty, opts.raw)
+	})
+	fmt.Fprintf(os.Stderr, "Streamed %d value(s)\n", n)
+	return err
+}
+
+// decodeJSONStream passes consecutive JSON values from r to emit without
+// buffering the whole body. With splitArray, a top-level array is streamed
+// element by element.
+func decodeJSONStream(r io.Reader, splitArray bool, emit func(json.RawMessage) error) (int, error) {
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+	count := 0
+
+	if splitArray && peekNonSpace(br) == '[' {
+		if _, err := dec.Token(); err != nil {
+			return 0, fmt.Errorf("read response: %w", err)
+		}
+		for dec.More() {
+			var v json.RawMessage
+			if err := dec.Decode(&v); err != nil {
+				return count, fmt.Errorf("value %d: %w", count+1, err)
+			}
+			if err := emit(v); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if _, err := dec.Token(); err != nil {
+			return count, fmt.Errorf("read response: %w", err)
+		}
+		return count, nil
+	}
+
+	for {
+		var v json.RawMessage
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("value %d: %w", count+1, err)
+		}
+		if err := emit(v); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// peekNonSpace returns the first non-whitespace byte without consuming it
+// (0 at EOF).
+func peekNonSpace(br *bufio.Reader) byte {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return b[0]
+		}
+	}
+}
+
+// writeJSONItem writes one JSON value (after the optional filter) followed
+// by a newline. Without --pretty the value is compacted onto one line.
+func writeJSONItem(w io.Writer, data []byte, f *filter.Filter, pretty, raw bool) error {
+	var buf bytes.Buffer
+	switch {
+	case f != nil:
+		out, err := applyFilter(f, data, pretty, raw)
+		if err != nil {
+			return err
+		}
+		buf.Write(out)
+	case pretty:
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err
+		}
+	default:
+		if err := json.Compact(&buf, data); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// printStreamHead prints the status and headers before streamed output.
+func printStreamHead(kind string, resp *http.Response) {
+	fmt.Printf("\n=== Response (%s) ===\n", kind)
+	fmt.Printf("Status: %s\n", resp.Status)
+	for _, k := range output.SortedHeaderKeys(resp.Header) {
+		fmt.Printf("%s: %s\n", k, strings.Join(resp.Header[k], ", "))
+	}
+	fmt.Println()
+}
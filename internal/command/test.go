@@ -0,0 +1,713 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go-rest-api-cli-demo/internal/assert"
+	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/jsonschema"
+	"go-rest-api-cli-demo/internal/junit"
+	"go-rest-api-cli-demo/internal/payload"
+	"go-rest-api-cli-demo/internal/vars"
+	"go-rest-api-cli-demo/internal/yaml"
+)
+
+// TestCommand = "test" subcommand: run YAML test suites (requests with
+// assertions, setup/teardown and captures) and report the results.
+type TestCommand struct {
+	Factory httpclient.Factory
+}
+
+func NewTestCommand(factory httpclient.Factory) *TestCommand {
+	return &TestCommand{Factory: factory}
+}
+
+func (t *TestCommand) Name() string { return "test" }
+func (t *TestCommand) Description() string {
+	return "Run YAML test suites with assertions (summary, JUnit XML)"
+}
+
+// testSuite is one suite file.
+type testSuite struct {
+	Name     string      `json:"name"`
+	Profile  string      `json:"profile"`
+	BaseURL  string      `json:"base_url"`
+	Vars     scalarMap   `json:"vars"`
+	Headers  scalarMap   `json:"headers"`
+	Setup    []*testStep `json:"setup"`
+	Tests    []*testStep `json:"tests"`
+	Teardown []*testStep `json:"teardown"`
+
+	// Snapshot ignore rules shared by every step
+	SnapshotIgnore        []string `json:"snapshot_ignore"`
+	SnapshotIgnoreHeaders []string `json:"snapshot_ignore_headers"`
+
+	path string
+}
+
+// scalarMap is a map of text values (vars, query, headers, form) that also
+// accepts YAML numbers and booleans: {page: 2} is {"page": "2"}.
+type scalarMap map[string]string
+
+func (m *scalarMap) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	out := make(scalarMap, len(raw))
+	for k, v := range raw {
+		v = bytes.TrimSpace(v)
+		switch {
+		case len(v) > 0 && v[0] == '"':
+			var s string
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			out[k] = s
+		case string(v) == "null":
+			out[k] = ""
+		case len(v) > 0 && (v[0] == '{' || v[0] == '['):
+			return fmt.Errorf("%s: want a string, number or boolean, got %s", k, v)
+		default:
+			out[k] = string(v) // number or boolean, as written
+		}
+	}
+	*m = out
+	return nil
+}
+
+// testStep is one request of a suite, with what to expect and capture.
+type testStep struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Query   scalarMap         `json:"query"`
+	Headers scalarMap         `json:"headers"`
+	JSON    *json.RawMessage  `json:"json"`
+	Body    *string           `json:"body"`
+	Form    scalarMap         `json:"form"`
+	Timeout string            `json:"timeout"`
+	Skip    interface{}       `json:"skip"` // true or a reason
+	Expect  testExpect        `json:"expect"`
+	Capture map[string]string `json:"capture"`
+
+	// Snapshot file (relative to the suite) and what it leaves out
+	Snapshot              string   `json:"snapshot"`
+	SnapshotIgnore        []string `json:"snapshot_ignore"`
+	SnapshotIgnoreHeaders []string `json:"snapshot_ignore_headers"`
+
+	assertions   []assert.Assertion
+	captures     CaptureFlag
+	snapshotPath string
+	snapshotOpts snapshotOptions
+}
+
+// testExpect is the "expect" block of a step.
+type testExpect struct {
+	Status  interface{}            `json:"status"` // 200, "2xx", "201,204"
+	Headers map[string]interface{} `json:"headers"`
+	JSON    map[string]interface{} `json:"json"`
+	Body    interface{}            `json:"body"`
+	MaxTime interface{}            `json:"max_time"` // ms or a duration
+	Schema  string                 `json:"schema"`
+}
+
+// stepResult is the outcome of one step.
+type stepResult struct {
+	name     string
+	duration time.Duration
+	skipped  string   // reason, when the step did not run
+	note     string   // e.g. "snapshot written"
+	err      error    // the request could not be sent
+	failures []string // assertions (and captures) that did not hold
+	trace    string   // request/response summary for failures
+}
+
+func (r stepResult) ok() bool { return r.skipped == "" && r.err == nil && len(r.failures) == 0 }
+
+func (t *TestCommand) Run(args []string) error {
+	// Allow "test FILE... [flags]" as well as flags first
+	var paths []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		paths, args = append(paths, args[0]), args[1:]
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var (
+		profileName = fs.String("profile", "", "Profile for every suite (overrides the suite's profile)")
+		junitPath   = fs.String("junit", "", "Write a JUnit XML report to FILE")
+		only        = fs.String("name", "", "Only run tests whose name contains this text")
+		bail        = fs.Bool("bail", false, "Stop a suite at its first failed test")
+		verbose     = fs.Bool("verbose", false, "Print request and response of failed steps")
+		update      = fs.Bool("update-snapshots", false, "Rewrite the snapshot files of the steps that run")
+		timeoutSec  = fs.Int("timeout", 30, "Timeout in seconds (per request)")
+		insecure    = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
+	)
+	cliVars := VarFlag{}
+	fs.Var(&cliVars, "var", "Variable 'name=value', overrides the suite's vars (can be repeated)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths = append(paths, fs.Args()...)
+	if len(paths) == 0 {
+		return fmt.Errorf("at least one suite file or directory is required")
+	}
+
+	files, err := suiteFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	report := &junit.Suites{Name: "go-rest-api-cli-demo test"}
+	hooks := 0 // setup and teardown steps among report.Tests
+	start := time.Now()
+	for _, file := range files {
+		suite, err := loadSuite(file)
+		if err != nil {
+			return err
+		}
+		run := &suiteRun{
+			cmd:     t,
+			suite:   suite,
+			only:    *only,
+			bail:    *bail,
+			verbose: *verbose,
+			update:  *update,
+			base: requestInput{
+				Profile:  suite.Profile,
+				Timeout:  time.Duration(*timeoutSec) * time.Second,
+				Insecure: *insecure,
+			},
+		}
+		if *profileName != "" {
+			run.base.Profile = *profileName
+		}
+		if err := run.initVars(cliVars); err != nil {
+			return err
+		}
+		report.Add(run.run())
+		hooks += run.hooks
+	}
+	report.Time = junit.Seconds(time.Since(start))
+
+	counted := fmt.Sprintf("%d test(s)", report.Tests)
+	if hooks > 0 {
+		counted += fmt.Sprintf(" (incl. %d setup/teardown step(s))", hooks)
+	}
+	fmt.Printf("\n%s: %d passed, %d failed, %d error(s), %d skipped (%.2fs)\n",
+		counted, report.Tests-report.Failures-report.Errors-report.Skipped,
+		report.Failures, report.Errors, report.Skipped, time.Since(start).Seconds())
+
+	if *junitPath != "" {
+		if err := report.WriteFile(*junitPath); err != nil {
+			return err
+		}
+		fmt.Printf("JUnit report written to %s\n", *junitPath)
+	}
+	// Same exit codes as call: 6 when an assertion failed, 3 when the
+	// only problems were requests that could not be sent
+	bad := report.Failures + report.Errors
+	switch {
+	case report.Failures > 0:
+		return &ExitError{Code: exitAssertion, Err: fmt.Errorf("%d of %s failed", bad, counted)}
+	case report.Errors > 0:
+		return &ExitError{Code: exitTransport, Err: fmt.Errorf("%d of %s could not be sent", bad, counted)}
+	}
+	return nil
+}
+
+// suiteFiles expands directories to the .yaml/.yml files they contain.
+func suiteFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, e := range entries {
+			if ext := strings.ToLower(filepath.Ext(e.Name())); !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				found = append(found, filepath.Join(p, e.Name()))
+			}
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("%s: no .yaml/.yml suites", p)
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// loadSuite reads a suite and compiles its expectations and captures, so
+// mistakes are reported before anything is sent.
+func loadSuite(path string) (*testSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read suite: %w", err)
+	}
+	converted, err := yaml.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(converted))
+	dec.DisallowUnknownFields()
+	suite := &testSuite{}
+	if err := dec.Decode(suite); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	suite.path = path
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if len(suite.Tests) == 0 {
+		return nil, fmt.Errorf("%s: no tests", path)
+	}
+
+	dir := filepath.Dir(path)
+	for section, steps := range map[string][]*testStep{"setup": suite.Setup, "tests": suite.Tests, "teardown": suite.Teardown} {
+		for i, step := range steps {
+			if step.URL == "" {
+				return nil, fmt.Errorf("%s: %s[%d]: url is required", path, section, i)
+			}
+			if step.Name == "" {
+				step.Name = strings.TrimSpace(strings.ToUpper(step.Method) + " " + step.URL)
+			}
+			if err := step.compile(dir, suite); err != nil {
+				return nil, fmt.Errorf("%s: %s %q: %w", path, section, step.Name, err)
+			}
+		}
+	}
+	return suite, nil
+}
+
+// compile turns the expect and capture blocks into assertions and
+// capture specs. Without a status expectation any status below 400 passes.
+func (s *testStep) compile(dir string, suite *testSuite) error {
+	status := "100-399"
+	switch v := s.Expect.Status.(type) {
+	case nil:
+	case float64:
+		status = fmt.Sprintf("%d", int(v))
+	case string:
+		status = v
+	default:
+		return fmt.Errorf("expect.status: want a code, 2xx or a list")
+	}
+	a, err := assert.Status(status)
+	if err != nil {
+		return fmt.Errorf("expect.status: %w", err)
+	}
+	s.assertions = append(s.assertions, a)
+
+	for _, name := range sortedKeys(s.Expect.Headers) {
+		m, err := assert.ParseMatcher(s.Expect.Headers[name])
+		if err != nil {
+			return fmt.Errorf("expect.headers.%s: %w", name, err)
+		}
+		s.assertions = append(s.assertions, assert.Header(name, m))
+	}
+	for _, path := range sortedKeys(s.Expect.JSON) {
+		m, err := assert.ParseMatcher(s.Expect.JSON[path])
+		if err != nil {
+			return fmt.Errorf("expect.json %s: %w", path, err)
+		}
+		s.assertions = append(s.assertions, assert.JSON(path, m))
+	}
+	if s.Expect.Body != nil {
+		m, err := assert.ParseMatcher(s.Expect.Body)
+		if err != nil {
+			return fmt.Errorf("expect.body: %w", err)
+		}
+		s.assertions = append(s.assertions, assert.Body(m))
+	}
+	switch v := s.Expect.MaxTime.(type) {
+	case nil:
+	case float64:
+		s.assertions = append(s.assertions, assert.MaxTime(time.Duration(v)*time.Millisecond))
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("expect.max_time: %w", err)
+		}
+		s.assertions = append(s.assertions, assert.MaxTime(d))
+	default:
+		return fmt.Errorf("expect.max_time: want milliseconds or a duration like 500ms")
+	}
+	if s.Expect.Schema != "" {
+		path := s.Expect.Schema
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		schema, err := jsonschema.Load(path)
+		if err != nil {
+			return fmt.Errorf("expect.schema: %w", err)
+		}
+		s.assertions = append(s.assertions, assert.Schema(s.Expect.Schema, schema))
+	}
+
+	if s.Snapshot != "" {
+		s.snapshotPath = s.Snapshot
+		if !filepath.IsAbs(s.snapshotPath) {
+			s.snapshotPath = filepath.Join(dir, s.snapshotPath)
+		}
+		opts, err := newSnapshotOptions(
+			append(append([]string(nil), suite.SnapshotIgnore...), s.SnapshotIgnore...),
+			append(append([]string(nil), suite.SnapshotIgnoreHeaders...), s.SnapshotIgnoreHeaders...),
+			false)
+		if err != nil {
+			return err
+		}
+		s.snapshotOpts = opts
+	}
+
+	for _, name := range sortedKeys(s.Capture) {
+		if err := s.captures.Set(name + "=" + s.Capture[name]); err != nil {
+			return err
+		}
+	}
+	if s.Timeout != "" {
+		if _, err := time.ParseDuration(s.Timeout); err != nil {
+			return fmt.Errorf("timeout: %w", err)
+		}
+	}
+	kinds := 0
+	for _, used := range []bool{s.JSON != nil, s.Body != nil, len(s.Form) > 0} {
+		if used {
+			kinds++
+		}
+	}
+	if kinds > 1 {
+		return fmt.Errorf("use only one of json, body and form")
+	}
+	return nil
+}
+
+// suiteRun holds the state of one suite while it runs.
+type suiteRun struct {
+	cmd     *TestCommand
+	suite   *testSuite
+	base    requestInput
+	vars    map[string]string
+	only    string
+	bail    bool
+	verbose bool
+	update  bool // rewrite snapshots
+	hooks   int  // setup and teardown steps recorded
+}
+
+// initVars expands the suite's vars (in name order, so later ones may use
+// earlier ones and template functions); --var values win.
+func (r *suiteRun) initVars(cli map[string]string) error {
+	r.vars = map[string]string{}
+	for _, name := range sortedKeys(r.suite.Vars) {
+		if v, ok := cli[name]; ok {
+			r.vars[name] = v
+			continue
+		}
+		v, err := vars.Expand(r.suite.Vars[name], r.vars)
+		if err != nil {
+			return fmt.Errorf("%s: vars.%s: %w", r.suite.path, name, err)
+		}
+		r.vars[name] = v
+	}
+	for k, v := range cli {
+		r.vars[k] = v
+	}
+	return nil
+}
+
+// run executes setup, tests and teardown. A failed setup skips the tests;
+// teardown always runs.
+func (r *suiteRun) run() junit.Suite {
+	suite := junit.Suite{Name: r.suite.Name, Timestamp: time.Now().Format(time.RFC3339)}
+	start := time.Now()
+	fmt.Printf("\n=== %s (%s) ===\n", r.suite.Name, r.suite.path)
+
+	record := func(prefix string, res stepResult) {
+		r.print(prefix, res)
+		if prefix != "" {
+			r.hooks++
+		}
+		suite.Cases = append(suite.Cases, r.junitCase(prefix, res))
+	}
+
+	setupFailed := ""
+	for _, step := range r.suite.Setup {
+		res := r.runStep(step)
+		record("setup: ", res)
+		if !res.ok() {
+			setupFailed = fmt.Sprintf("setup %q failed", step.Name)
+			break
+		}
+	}
+
+	failed := false
+	for _, step := range r.suite.Tests {
+		if r.only != "" && !strings.Contains(strings.ToLower(step.Name), strings.ToLower(r.only)) {
+			continue
+		}
+		var res stepResult
+		switch {
+		case setupFailed != "":
+			res = stepResult{name: step.Name, skipped: setupFailed}
+		case failed && r.bail:
+			res = stepResult{name: step.Name, skipped: "--bail after a failure"}
+		default:
+			res = r.runStep(step)
+		}
+		failed = failed || res.err != nil || len(res.failures) > 0
+		record("", res)
+	}
+
+	for _, step := range r.suite.Teardown {
+		record("teardown: ", r.runStep(step))
+	}
+
+	suite.Time = junit.Seconds(time.Since(start))
+	return suite
+}
+
+// runStep sends one request and checks the response.
+func (r *suiteRun) runStep(step *testStep) stepResult {
+	res := stepResult{name: step.Name}
+	switch skip := step.Skip.(type) {
+	case bool:
+		if skip {
+			res.skipped = "skipped"
+			return res
+		}
+	case string:
+		if skip != "" {
+			res.skipped = skip
+			return res
+		}
+	}
+
+	in, err := r.buildRequest(step)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	resolved, err := resolveRequest(in)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	req, client, err := r.cmd.Factory.Build(resolved.Config)
+	if err != nil {
+		res.err = fmt.Errorf("build request: %w", err)
+		return res
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		res.duration = time.Since(start)
+		res.err = err
+		return res
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	res.duration = time.Since(start)
+	if err != nil {
+		res.err = fmt.Errorf("read response: %w", err)
+		return res
+	}
+
+	for _, f := range assert.Check(&assert.Response{
+		Status:   resp.StatusCode,
+		Header:   resp.Header,
+		Body:     body,
+		Duration: res.duration,
+	}, step.assertions) {
+		res.failures = append(res.failures, f.String())
+	}
+	if step.snapshotPath != "" {
+		opts := step.snapshotOpts
+		opts.update = r.update
+		changes, written, err := compareSnapshot(step.snapshotPath, opts, resp.StatusCode, resp.Header, body)
+		switch {
+		case err != nil:
+			res.failures = append(res.failures, "snapshot: "+err.Error())
+		case written:
+			res.note = "snapshot written"
+		}
+		for _, c := range changes {
+			res.failures = append(res.failures, "snapshot "+c.String())
+		}
+	}
+	for _, c := range step.captures {
+		v, err := c.extract(resp.Header, body)
+		if err != nil {
+			res.failures = append(res.failures, fmt.Sprintf("capture %s: %v", c.Name, err))
+			continue
+		}
+		r.vars[c.Name] = v
+	}
+
+	res.trace = fmt.Sprintf("> %s %s\n< %s\n%s", req.Method, req.URL, resp.Status, truncate(bodyPreview(body), 2000))
+	return res
+}
+
+// buildRequest expands a step's placeholders and assembles its body.
+func (r *suiteRun) buildRequest(step *testStep) (requestInput, error) {
+	in := r.base
+	expand := func(what, s string) (string, error) {
+		out, err := vars.Expand(s, r.vars)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", what, err)
+		}
+		return out, nil
+	}
+
+	urlStr, err := expand("url", step.URL)
+	if err != nil {
+		return in, err
+	}
+	if r.suite.BaseURL != "" && !strings.Contains(urlStr, "://") {
+		base, err := expand("base_url", r.suite.BaseURL)
+		if err != nil {
+			return in, err
+		}
+		urlStr = strings.TrimRight(base, "/") + "/" + strings.TrimLeft(urlStr, "/")
+	}
+	if len(step.Query) > 0 {
+		q := url.Values{}
+		for _, k := range sortedKeys(step.Query) {
+			v, err := expand("query "+k, step.Query[k])
+			if err != nil {
+				return in, err
+			}
+			q.Add(k, v)
+		}
+		sep := "?"
+		if strings.Contains(urlStr, "?") {
+			sep = "&"
+		}
+		urlStr += sep + q.Encode()
+	}
+
+	headers := map[string]string{}
+	for _, src := range []map[string]string{r.suite.Headers, step.Headers} {
+		for k, v := range src {
+			if headers[k], err = expand("header "+k, v); err != nil {
+				return in, err
+			}
+		}
+	}
+
+	method := strings.ToUpper(step.Method)
+	switch {
+	case step.JSON != nil:
+		text, err := expand("json", string(*step.JSON))
+		if err != nil {
+			return in, err
+		}
+		if !json.Valid([]byte(text)) {
+			return in, fmt.Errorf("json: not valid JSON after expanding placeholders")
+		}
+		in.Body = []byte(text)
+	case step.Body != nil:
+		text, err := expand("body", *step.Body)
+		if err != nil {
+			return in, err
+		}
+		in.Body, in.BodyType = []byte(text), sniffContentType([]byte(text))
+	case len(step.Form) > 0:
+		pairs := make([]string, 0, len(step.Form))
+		for _, k := range sortedKeys(step.Form) {
+			v, err := expand("form "+k, step.Form[k])
+			if err != nil {
+				return in, err
+			}
+			pairs = append(pairs, k+"="+v)
+		}
+		encoded, err := payload.EncodeForm(pairs)
+		if err != nil {
+			return in, err
+		}
+		in.Body, in.BodyType = []byte(encoded), "application/x-www-form-urlencoded"
+	}
+	if method == "" {
+		method = "GET"
+		if len(in.Body) > 0 {
+			method = "POST"
+		}
+	}
+	if step.Timeout != "" {
+		in.Timeout, _ = time.ParseDuration(step.Timeout) // checked in compile
+	}
+
+	in.Method, in.URL, in.Headers = method, urlStr, headers
+	return in, nil
+}
+
+func (r *suiteRun) print(prefix string, res stepResult) {
+	switch {
+	case res.skipped != "":
+		fmt.Printf("  SKIP  %s%s (%s)\n", prefix, res.name, res.skipped)
+		return
+	case res.err != nil:
+		fmt.Printf("  ERROR %s%s: %v\n", prefix, res.name, res.err)
+		return
+	case res.ok() && res.note != "":
+		fmt.Printf("  PASS  %s%s (%d ms, %s)\n", prefix, res.name, res.duration.Milliseconds(), res.note)
+		return
+	case res.ok():
+		fmt.Printf("  PASS  %s%s (%d ms)\n", prefix, res.name, res.duration.Milliseconds())
+		return
+	}
+	fmt.Printf("  FAIL  %s%s (%d ms)\n", prefix, res.name, res.duration.Milliseconds())
+	for _, f := range res.failures {
+		fmt.Printf("        %s\n", f)
+	}
+	if r.verbose && res.trace != "" {
+		for _, line := range strings.Split(res.trace, "\n") {
+			fmt.Printf("        | %s\n", line)
+		}
+	}
+}
+
+func (r *suiteRun) junitCase(prefix string, res stepResult) junit.Case {
+	c := junit.Case{Name: prefix + res.name, Classname: r.suite.Name, Time: junit.Seconds(res.duration)}
+	switch {
+	case res.skipped != "":
+		c.Skipped = &junit.Skipped{Message: res.skipped}
+	case res.err != nil:
+		c.Error = &junit.Result{Message: res.err.Error(), Type: "request"}
+	case len(res.failures) > 0:
+		c.Failure = &junit.Result{
+			Message: fmt.Sprintf("%d assertion(s) failed", len(res.failures)),
+			Type:    "assertion",
+			Text:    strings.Join(res.failures, "\n"),
+		}
+		c.SystemOut = res.trace
+	}
+	return c
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
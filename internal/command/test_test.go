@@ -0,0 +1,317 @@
+package command
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-rest-api-cli-demo/internal/httpclient"
+)
+
+// testAPI is a small in-memory API for the suites below. It records every
+// request as "METHOD /path?query".
+type testAPI struct {
+	mu   sync.Mutex
+	hits []string
+}
+
+func (a *testAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	hit := r.Method + " " + r.URL.Path
+	if r.URL.RawQuery != "" {
+		hit += "?" + r.URL.RawQuery
+	}
+	a.hits = append(a.hits, hit)
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/login":
+		fmt.Fprint(w, `{"token":"t-1"}`)
+	case r.Header.Get("Authorization") != "Bearer t-1":
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message":"unauthorized"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/users":
+		var in map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		in["id"] = 7
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(in)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/users/"):
+		id := strings.TrimPrefix(r.URL.Path, "/users/")
+		fmt.Fprintf(w, `{"id":%s,"name":"Ada","page":%q}`, id, r.URL.Query().Get("page"))
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (a *testAPI) requests() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.hits...)
+}
+
+// junitReport is the part of a JUnit report the tests look at.
+type junitReport struct {
+	Tests    int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors   int `xml:"errors,attr"`
+	Skipped  int `xml:"skipped,attr"`
+	Suites   []struct {
+		Name  string `xml:"name,attr"`
+		Cases []struct {
+			Name    string    `xml:"name,attr"`
+			Failure *struct{} `xml:"failure"`
+			Error   *struct{} `xml:"error"`
+			Skipped *struct {
+				Message string `xml:"message,attr"`
+			} `xml:"skipped"`
+		} `xml:"testcase"`
+	} `xml:"testsuite"`
+}
+
+// runSuite writes suite (with {{base}} replaced by the server URL) to a
+// temporary file and runs the test command on it with args. It returns
+// the JUnit report and the error of Run.
+func runSuite(t *testing.T, api *testAPI, suite string, args ...string) (junitReport, error) {
+	t.Helper()
+	srv := httptest.NewServer(api)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "suite.yaml")
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(suite, "{{base}}", srv.URL)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(dir, "report.xml")
+
+	runErr := NewTestCommand(httpclient.Factory{}).Run(append([]string{path, "--junit", report}, args...))
+
+	var out junitReport
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("no JUnit report: %v", err)
+	}
+	if err := xml.Unmarshal(data, &out); err != nil {
+		t.Fatalf("JUnit report: %v", err)
+	}
+	return out, runErr
+}
+
+// wantExit fails t unless err is an ExitError with code and message.
+func wantExit(t *testing.T, err error, code int, message string) {
+	t.Helper()
+	var exit *ExitError
+	if !errors.As(err, &exit) || exit.Code != code || err.Error() != message {
+		t.Fatalf("Run error = %v, want exit code %d with %q", err, code, message)
+	}
+}
+
+func TestTestCommandPasses(t *testing.T) {
+	api := &testAPI{}
+	report, err := runSuite(t, api, `
+name: users
+base_url: {{base}}
+vars:
+  id: 42
+headers:
+  Accept: application/json
+setup:
+  - name: login
+    method: POST
+    url: /login
+    json: {user: ada}
+    capture:
+      token: $.token
+tests:
+  - name: create user
+    url: /users
+    headers:
+      Authorization: Bearer {{token}}
+    json: {name: "{{fake.firstName}}", admin: false}
+    expect:
+      status: 201
+      headers:
+        Content-Type: {contains: json}
+      json:
+        $.id: 7
+        $.admin: false
+    capture:
+      created: $.id
+  - name: get user
+    url: /users/{{id}}
+    query: {page: 2}
+    headers:
+      Authorization: Bearer {{token}}
+    expect:
+      status: 2xx
+      json:
+        $.id: 42
+        $.page: "2"
+        $.name: {matches: "^A", length: 3}
+  - name: not ready
+    url: /users/1
+    skip: not implemented yet
+teardown:
+  - name: delete user
+    method: DELETE
+    url: /users/{{created}}
+    headers:
+      Authorization: Bearer {{token}}
+    expect:
+      status: 204
+`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := []string{"POST /login", "POST /users", "GET /users/42?page=2", "DELETE /users/7"}
+	if got := api.requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+	if report.Tests != 5 || report.Failures != 0 || report.Errors != 0 || report.Skipped != 1 {
+		t.Errorf("report = %d tests, %d failures, %d errors, %d skipped; want 5, 0, 0, 1",
+			report.Tests, report.Failures, report.Errors, report.Skipped)
+	}
+	var names []string
+	for _, c := range report.Suites[0].Cases {
+		names = append(names, c.Name)
+	}
+	wantNames := []string{"setup: login", "create user", "get user", "not ready", "teardown: delete user"}
+	if strings.Join(names, "|") != strings.Join(wantNames, "|") {
+		t.Errorf("cases = %q, want %q", names, wantNames)
+	}
+	if s := report.Suites[0].Cases[3].Skipped; s == nil || s.Message != "not implemented yet" {
+		t.Errorf("skipped case = %+v, want the skip reason", s)
+	}
+}
+
+const failingSuite = `
+base_url: {{base}}
+setup:
+  - url: /login
+    method: POST
+    capture:
+      token: $.token
+tests:
+  - name: wrong name
+    url: /users/1
+    headers: {Authorization: "Bearer {{token}}"}
+    expect:
+      json: {$.name: Bob}
+  - name: unauthorized
+    url: /users/2
+  - name: last
+    url: /users/3
+    headers: {Authorization: "Bearer {{token}}"}
+teardown:
+  - method: DELETE
+    url: /users/1
+    headers: {Authorization: "Bearer {{token}}"}
+`
+
+func TestTestCommandFailures(t *testing.T) {
+	api := &testAPI{}
+	report, err := runSuite(t, api, failingSuite)
+	wantExit(t, err, exitAssertion, "2 of 5 test(s) (incl. 2 setup/teardown step(s)) failed")
+	if report.Failures != 2 || report.Skipped != 0 {
+		t.Errorf("report = %d failures, %d skipped; want 2, 0", report.Failures, report.Skipped)
+	}
+	if got := api.requests(); len(got) != 5 {
+		t.Errorf("requests = %q, want all 5 steps sent", got)
+	}
+}
+
+func TestTestCommandBail(t *testing.T) {
+	api := &testAPI{}
+	report, err := runSuite(t, api, failingSuite, "--bail")
+	wantExit(t, err, exitAssertion, "1 of 5 test(s) (incl. 2 setup/teardown step(s)) failed")
+	if report.Failures != 1 || report.Skipped != 2 {
+		t.Errorf("report = %d failures, %d skipped; want 1, 2", report.Failures, report.Skipped)
+	}
+	// Teardown still runs after --bail
+	want := []string{"POST /login", "GET /users/1", "DELETE /users/1"}
+	if got := api.requests(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %q, want %q", got, want)
+	}
+}
+
+func TestTestCommandSetupFailure(t *testing.T) {
+	api := &testAPI{}
+	report, err := runSuite(t, api, `
+base_url: {{base}}
+setup:
+  - name: profile
+    url: /users/1
+tests:
+  - url: /users/2
+  - url: /users/3
+`)
+	wantExit(t, err, exitAssertion, "1 of 3 test(s) (incl. 1 setup/teardown step(s)) failed")
+	if report.Failures != 1 || report.Skipped != 2 {
+		t.Errorf("report = %d failures, %d skipped; want 1, 2", report.Failures, report.Skipped)
+	}
+	for _, c := range report.Suites[0].Cases[1:] {
+		if c.Skipped == nil || c.Skipped.Message != `setup "profile" failed` {
+			t.Errorf("case %q: want it skipped after the failed setup", c.Name)
+		}
+	}
+	if got := api.requests(); len(got) != 1 {
+		t.Errorf("requests = %q, want only the setup", got)
+	}
+}
+
+func TestTestCommandRequestError(t *testing.T) {
+	// A server that is gone gives an error case, not a failure
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	report, err := runSuite(t, &testAPI{}, `
+tests:
+  - url: `+srv.URL+`/gone
+`)
+	wantExit(t, err, exitTransport, "1 of 1 test(s) could not be sent")
+	if report.Errors != 1 || report.Failures != 0 || report.Suites[0].Cases[0].Error == nil {
+		t.Errorf("report = %d errors, %d failures; want 1, 0", report.Errors, report.Failures)
+	}
+}
+
+func TestTestCommandInvalidSuite(t *testing.T) {
+	tests := []struct {
+		name  string
+		suite string
+		want  string
+	}{
+		{"no tests", "name: x\n", "no tests"},
+		{"missing url", "tests:\n  - name: a\n", "url is required"},
+		{"bad status", "tests:\n  - url: /a\n    expect: {status: 2yy}\n", "expect.status"},
+		{"two bodies", "tests:\n  - url: /a\n    json: {}\n    body: x\n", "only one of json, body and form"},
+		{"unknown field", "tests:\n  - url: /a\n    expects: {}\n", "unknown field"},
+		{"object var", "vars: {a: {b: 1}}\ntests:\n  - url: /a\n", "want a string, number or boolean"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "suite.yaml")
+			if err := os.WriteFile(path, []byte(tt.suite), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := NewTestCommand(httpclient.Factory{}).Run([]string{path})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Run error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
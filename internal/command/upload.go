@@ -0,0 +1,247 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"go-rest-api-cli-demo/internal/form"
+	"go-rest-api-cli-demo/internal/vars"
+)
+
+// progressThreshold is the body size from which the upload progress is shown
+// automatically (on a terminal).
+const progressThreshold = 1 << 20
+
+// buildUpload turns --form and --file values into a multipart body, or
+// returns nil when neither was given. Field values and paths may contain
+// {{name}} placeholders.
+func buildUpload(fields, files ListFlag, variables map[string]string) (*form.Body, error) {
+	if len(fields) == 0 && len(files) == 0 {
+		return nil, nil
+	}
+
+	var (
+		ff []form.Field
+		fl []form.File
+	)
+	for _, s := range fields {
+		expanded, err := vars.Expand(s, variables)
+		if err != nil {
+			return nil, fmt.Errorf("--form: %w", err)
+		}
+		f, err := form.ParseField(expanded)
+		if err != nil {
+			return nil, err
+		}
+		ff = append(ff, f)
+	}
+	for _, s := range files {
+		expanded, err := vars.Expand(s, variables)
+		if err != nil {
+			return nil, fmt.Errorf("--file: %w", err)
+		}
+		f, err := form.ParseFile(expanded)
+		if err != nil {
+			return nil, err
+		}
+		fl = append(fl, f)
+	}
+	return form.New(ff, fl)
+}
+
+// applyUpload makes cfg stream the multipart body. An explicit Content-Type
+// header is kept; a multipart one gets the boundary of the body.
+func applyUpload(res *resolvedRequest, body *form.Body, showProgress bool) {
+	if explicit, ok := res.Config.Headers["Content-Type"]; ok {
+		if mt, params, err := mime.ParseMediaType(explicit); err == nil && strings.HasPrefix(mt, "multipart/") {
+			params["boundary"] = body.Boundary()
+			res.Config.Headers["Content-Type"] = mime.FormatMediaType(mt, params)
+		}
+	} else {
+		res.Config.Headers["Content-Type"] = body.ContentType()
+		res.HeaderSources["Content-Type"] = sourceDefault + " (multipart body)"
+	}
+	res.BodySummary = body.Summary()
+
+	res.Config.BodySize = body.Size()
+	res.Config.BodyStream = func() (io.ReadCloser, error) {
+		var progress func(int64)
+		if showProgress || (body.Size() >= progressThreshold && isTerminal(os.Stderr)) {
+			progress = newProgress("Uploading", body.Size())
+		}
+		return body.Open(progress), nil
+	}
+}
+
+// newProgress returns a callback that redraws a one-line progress indicator
+// on stderr, at most a few times per second.
+func newProgress(label string, total int64) func(int64) {
+	var (
+		mu   sync.Mutex
+		last time.Time
+		done bool
+	)
+	return func(sent int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		if done {
+			return
+		}
+		finished := sent >= total
+		if !finished && time.Since(last) < 200*time.Millisecond {
+			return
+		}
+		last = time.Now()
+
+		pct := 100
+		if total > 0 {
+			pct = int(sent * 100 / total)
+		}
+		fmt.Fprintf(os.Stderr, "\r%s %3d%% %s / %s", label, pct, form.FormatBytes(sent), form.FormatBytes(total))
+		if finished {
+			fmt.Fprintln(os.Stderr)
+			done = true
+		}
+	}
+}
+
+// isTerminal reports whether f is a character device (an interactive
+// terminal rather than a file or pipe).
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// applyBodyFile makes cfg stream the raw bytes of path ("-" = stdin). The
+// Content-Type is guessed from the extension or the content unless a
+// header or profile sets it.
+func applyBodyFile(res *resolvedRequest, path string, showProgress bool) error {
+	var (
+		size        int64 = -1
+		contentType       = "application/octet-stream"
+		open        func() (io.ReadCloser, error)
+	)
+	if path == "-" {
+		// stdin can be read only once, so a retry cannot resend it
+		var used atomic.Bool
+		open = func() (io.ReadCloser, error) {
+			if !used.CompareAndSwap(false, true) {
+				return nil, fmt.Errorf("a body read from stdin cannot be sent again")
+			}
+			return io.NopCloser(os.Stdin), nil
+		}
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("--body-file: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("--body-file: %s is a directory", path)
+		}
+		size = info.Size()
+		if ct, err := detectFileType(path); err == nil {
+			contentType = ct
+		}
+		open = func() (io.ReadCloser, error) { return os.Open(path) }
+	}
+
+	if _, ok := res.Config.Headers["Content-Type"]; !ok {
+		res.Config.Headers["Content-Type"] = contentType
+		res.HeaderSources["Content-Type"] = sourceDefault + " (body file)"
+	}
+	if size >= 0 {
+		res.BodySummary = fmt.Sprintf("raw bytes of %s (%s)", path, form.FormatBytes(size))
+	} else {
+		res.BodySummary = "raw bytes from stdin"
+	}
+
+	res.Config.BodySize = size
+	res.Config.BodyStream = func() (io.ReadCloser, error) {
+		body := &lazyBody{open: open}
+		if size >= 0 && (showProgress || (size >= progressThreshold && isTerminal(os.Stderr))) {
+			body.progress = newProgress("Uploading", size)
+		}
+		return body, nil
+	}
+	return nil
+}
+
+// detectFileType guesses a Content-Type from the extension, then from the
+// first bytes of the file.
+func detectFileType(path string) (string, error) {
+	if ct := mime.TypeByExtension(filepath.Ext(path)); ct != "" {
+		return ct, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return sniffContentType(head[:n]), nil
+}
+
+// sniffContentType guesses the type of a raw body: JSON, or whatever
+// net/http recognizes (XML, HTML, text, images, ...).
+func sniffContentType(data []byte) string {
+	if json.Valid(data) {
+		return "application/json"
+	}
+	ct := http.DetectContentType(data)
+	if strings.HasPrefix(ct, "text/plain") && strings.HasPrefix(strings.TrimSpace(string(data)), "<") {
+		return "application/xml" // e.g. a SOAP envelope without <?xml ...?>
+	}
+	return ct
+}
+
+// lazyBody opens its source on the first Read, so building a request (e.g.
+// for the preview) does not touch the file.
+type lazyBody struct {
+	open     func() (io.ReadCloser, error)
+	rc       io.ReadCloser
+	err      error
+	progress func(int64)
+	sent     int64
+}
+
+func (b *lazyBody) Read(p []byte) (int, error) {
+	if b.rc == nil && b.err == nil {
+		b.rc, b.err = b.open()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.rc.Read(p)
+	if n > 0 && b.progress != nil {
+		b.sent += int64(n)
+		b.progress(b.sent)
+	}
+	return n, err
+}
+
+func (b *lazyBody) Close() error {
+	if b.rc != nil {
+		return b.rc.Close()
+	}
+	return nil
+}
+
+// bodyPreview returns the body for request previews; binary content is
+// summarized instead of printed.
+func bodyPreview(body []byte) string {
+	if utf8.Valid(body) {
+		return string(body)
+	}
+	return fmt.Sprintf("<%s of binary data>", form.FormatBytes(int64(len(body))))
+}
@@ -0,0 +1,36 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VarFlag implements flag.Value for repeated --var flags.
+type VarFlag map[string]string
+
+func (v *VarFlag) String() string {
+	if *v == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(*v))
+	for k, val := range *v {
+		parts = append(parts, fmt.Sprintf("%s=%s", k, val))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (v *VarFlag) Set(value string) error {
+	if *v == nil {
+		*v = make(map[string]string)
+	}
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid variable, expected 'name=value'")
+	}
+	key := strings.TrimSpace(parts[0])
+	if key == "" {
+		return fmt.Errorf("variable name cannot be empty")
+	}
+	(*v)[key] = parts[1]
+	return nil
+}
@@ -0,0 +1,413 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go-rest-api-cli-demo/internal/httpclient"
+	"go-rest-api-cli-demo/internal/vars"
+	"go-rest-api-cli-demo/internal/websocket"
+)
+
+// WSCommand = "ws" subcommand: a WebSocket client for realtime endpoints.
+type WSCommand struct {
+	Factory httpclient.Factory
+}
+
+func NewWSCommand(factory httpclient.Factory) *WSCommand {
+	return &WSCommand{Factory: factory}
+}
+
+func (w *WSCommand) Name() string        { return "ws" }
+func (w *WSCommand) Description() string { return "Open a WebSocket, send messages and print frames" }
+
+// wsStop is why a session ended on our side.
+type wsStop string
+
+func (w *WSCommand) Run(args []string) error {
+	fs := flag.NewFlagSet("ws", flag.ContinueOnError)
+
+	var (
+		urlStr      = fs.String("url", "", "ws://, wss:// or http(s) URL (relative with --profile)")
+		profileName = fs.String("profile", "", "Profile name to use from config")
+		timeoutSec  = fs.Int("timeout", 30, "Handshake timeout in seconds")
+		insecure    = fs.Bool("insecure", false, "Skip TLS verification (NOT recommended for prod)")
+		subprotocol = fs.String("subprotocol", "", "Comma-separated Sec-WebSocket-Protocol values")
+
+		authType = fs.String("auth", "none", "Auth: none|basic|bearer")
+		user     = fs.String("user", "", "Username for basic auth")
+		pass     = fs.String("pass", "", "Password for basic auth")
+		token    = fs.String("token", "", "Bearer token")
+
+		sendFile  = fs.String("send-file", "", "Send the content of FILE as one message")
+		useStdin  = fs.Bool("stdin", false, "Send each line read from stdin as a message")
+		binary    = fs.Bool("binary", false, "Send messages as binary frames")
+		pingSec   = fs.Int("ping", 0, "Send a ping every N seconds (0 = off)")
+		maxMsgs   = fs.Int("max-messages", 0, "Close after receiving N messages (0 = no limit)")
+		untilStr  = fs.String("until", "", "Close after a received message matches this regex")
+		duration  = fs.Int("duration", 0, "Close after N seconds (0 = no limit)")
+		raw       = fs.Bool("raw", false, "Print only received message payloads")
+		outFormat = fs.String("output", "text", "Output format: text|json (one JSON object per frame)")
+		varsFile  = fs.String("vars-file", vars.DefaultFile, "Variables file for {{name}} placeholders")
+	)
+
+	headers := HeaderFlag{}
+	fs.Var(&headers, "header", "HTTP header 'Key: Value' for the handshake (can be repeated)")
+	sends := ListFlag{}
+	fs.Var(&sends, "send", "Message to send after connecting (can be repeated)")
+	cliVars := VarFlag{}
+	fs.Var(&cliVars, "var", "Variable 'name=value' for {{name}} placeholders (can be repeated)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *urlStr == "" {
+		return fmt.Errorf("--url is required")
+	}
+	jsonOut := false
+	switch strings.ToLower(*outFormat) {
+	case "text":
+	case "json":
+		jsonOut = true
+	default:
+		return fmt.Errorf("ws supports --output text or json")
+	}
+
+	var until *regexp.Regexp
+	if *untilStr != "" {
+		re, err := regexp.Compile(*untilStr)
+		if err != nil {
+			return fmt.Errorf("--until: %w", err)
+		}
+		until = re
+	}
+
+	// Variables: vars file, overridden by --var
+	variables, err := vars.LoadFile(*varsFile)
+	if err != nil {
+		return fmt.Errorf("load variables: %w", err)
+	}
+	for k, v := range cliVars {
+		variables[k] = v
+	}
+	if *urlStr, err = vars.Expand(*urlStr, variables); err != nil {
+		return fmt.Errorf("--url: %w", err)
+	}
+	for k, v := range headers {
+		if headers[k], err = vars.Expand(v, variables); err != nil {
+			return fmt.Errorf("header %s: %w", k, err)
+		}
+	}
+
+	// Messages to send right after connecting
+	var outgoing []string
+	for _, m := range sends {
+		expanded, err := vars.Expand(m, variables)
+		if err != nil {
+			return fmt.Errorf("--send: %w", err)
+		}
+		outgoing = append(outgoing, expanded)
+	}
+	if *sendFile != "" {
+		data, err := os.ReadFile(*sendFile)
+		if err != nil {
+			return fmt.Errorf("reading send-file: %w", err)
+		}
+		expanded, err := vars.Expand(string(data), variables)
+		if err != nil {
+			return fmt.Errorf("send-file: %w", err)
+		}
+		outgoing = append(outgoing, expanded)
+	}
+
+	// The handshake is a normal GET, so profile URL, headers, auth and TLS
+	// settings apply exactly like they do for call.
+	resolved, err := resolveRequest(requestInput{
+		Method:   "GET",
+		URL:      wsToHTTP(*urlStr),
+		Profile:  *profileName,
+		Headers:  headers,
+		AuthType: *authType,
+		User:     *user,
+		Pass:     *pass,
+		Token:    *token,
+		Insecure: *insecure,
+	})
+	if err != nil {
+		return err
+	}
+	cfg := resolved.Config
+	cfg.URL = wsToHTTP(cfg.URL) // profile base URLs may use ws(s):// too
+
+	key, err := websocket.NewKey()
+	if err != nil {
+		return fmt.Errorf("websocket key: %w", err)
+	}
+	var protocols []string
+	for _, p := range strings.Split(*subprotocol, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			protocols = append(protocols, p)
+		}
+	}
+	for k, v := range websocket.HandshakeHeaders(key, protocols) {
+		cfg.Headers[k] = v
+	}
+
+	req, client, err := w.Factory.Build(cfg)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	// Client.Timeout would also end the upgraded connection, so only the
+	// handshake is bounded by --timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handshakeTimer := time.AfterFunc(time.Duration(*timeoutSec)*time.Second, cancel)
+	resp, err := client.Do(req.WithContext(ctx))
+	handshakeTimer.Stop()
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	conn, err := websocket.Upgrade(resp, key)
+	if err != nil {
+		return err
+	}
+
+	wsURL := httpToWS(req.URL.String())
+	if p := resp.Header.Get("Sec-Websocket-Protocol"); p != "" {
+		fmt.Fprintf(os.Stderr, "Connected to %s (subprotocol %s)\n", wsURL, p)
+	} else {
+		fmt.Fprintf(os.Stderr, "Connected to %s\n", wsURL)
+	}
+
+	log := &frameLog{w: os.Stdout, json: jsonOut, raw: *raw}
+	conn.OnPong = func(data []byte) { log.print("<", websocket.OpPong, data) }
+
+	sendOp := websocket.OpText
+	if *binary {
+		sendOp = websocket.OpBinary
+	}
+	send := func(msg string) error {
+		log.print(">", sendOp, []byte(msg))
+		return conn.WriteMessage(sendOp, []byte(msg))
+	}
+
+	// Reader: all incoming messages flow through one channel
+	type incoming struct {
+		op   websocket.Opcode
+		data []byte
+	}
+	msgs := make(chan incoming)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			op, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			msgs <- incoming{op, data}
+		}
+	}()
+
+	for _, m := range outgoing {
+		if err := send(m); err != nil {
+			conn.Close(websocket.CloseNormal, "")
+			return err
+		}
+	}
+
+	stop := make(chan wsStop, 4)
+	if *useStdin {
+		go func() {
+			sc := bufio.NewScanner(os.Stdin)
+			sc.Buffer(make([]byte, 64*1024), 16<<20)
+			for sc.Scan() {
+				line, err := vars.Expand(sc.Text(), variables)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "Error:", err)
+					continue
+				}
+				if err := send(line); err != nil {
+					return
+				}
+			}
+			stop <- "stdin closed"
+		}()
+	}
+
+	var tick <-chan time.Time
+	if *pingSec > 0 {
+		ticker := time.NewTicker(time.Duration(*pingSec) * time.Second)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	var deadline <-chan time.Time
+	if *duration > 0 {
+		deadline = time.After(time.Duration(*duration) * time.Second)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	received := 0
+	var reason wsStop
+loop:
+	for {
+		select {
+		case m := <-msgs:
+			received++
+			log.print("<", m.op, m.data)
+			if *maxMsgs > 0 && received >= *maxMsgs {
+				reason = "--max-messages reached"
+				break loop
+			}
+			if until != nil && until.Match(m.data) {
+				reason = "--until matched"
+				break loop
+			}
+		case err := <-readErr:
+			return wsClosed(err, received)
+		case <-tick:
+			payload := []byte(time.Now().Format(time.RFC3339Nano))
+			log.print(">", websocket.OpPing, payload)
+			conn.Ping(payload)
+		case <-deadline:
+			reason = "--duration elapsed"
+			break loop
+		case <-interrupt:
+			reason = "interrupted"
+			break loop
+		case r := <-stop:
+			reason = r
+			break loop
+		}
+	}
+
+	// Closing handshake: wait briefly for the server's close frame
+	fmt.Fprintf(os.Stderr, "Closing (%s)\n", reason)
+	// (messages still in flight are printed meanwhile).
+	conn.SendClose(websocket.CloseNormal, "")
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case m := <-msgs:
+			received++
+			log.print("<", m.op, m.data)
+		case err := <-readErr:
+			return wsClosed(err, received)
+		case <-timeout:
+			conn.Close(websocket.CloseNormal, "")
+			fmt.Fprintf(os.Stderr, "Received %d message(s)\n", received)
+			return nil
+		}
+	}
+}
+
+// wsClosed turns the reader's final error into the command result: normal
+// closes are not errors.
+func wsClosed(err error, received int) error {
+	var ce *websocket.CloseError
+	if errors.As(err, &ce) {
+		fmt.Fprintf(os.Stderr, "Connection closed: %s; received %d message(s)\n", ce.Error(), received)
+		switch ce.Code {
+		case websocket.CloseNormal, 1001, websocket.CloseNoStatus:
+			return nil
+		}
+		return ce
+	}
+	if errors.Is(err, io.EOF) {
+		fmt.Fprintf(os.Stderr, "Connection dropped; received %d message(s)\n", received)
+		return nil
+	}
+	return fmt.Errorf("websocket read: %w", err)
+}
+
+// frameLog prints frames with timestamps and direction (> sent, < received).
+type frameLog struct {
+	mu   sync.Mutex
+	w    io.Writer
+	json bool
+	raw  bool
+}
+
+func (l *frameLog) print(dir string, op websocket.Opcode, data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	text := op != websocket.OpBinary
+	if l.json {
+		entry := struct {
+			Time     string `json:"time"`
+			Dir      string `json:"direction"`
+			Type     string `json:"type"`
+			Data     string `json:"data"`
+			Encoding string `json:"encoding,omitempty"`
+		}{now.Format(time.RFC3339Nano), "received", op.String(), string(data), ""}
+		if dir == ">" {
+			entry.Dir = "sent"
+		}
+		if !text {
+			entry.Data, entry.Encoding = base64.StdEncoding.EncodeToString(data), "base64"
+		}
+		line, _ := json.Marshal(entry)
+		fmt.Fprintln(l.w, string(line))
+		return
+	}
+
+	if l.raw {
+		// Payloads of received messages only, one per line
+		if dir == "<" && (op == websocket.OpText || op == websocket.OpBinary) {
+			l.w.Write(data)
+			fmt.Fprintln(l.w)
+		}
+		return
+	}
+
+	payload := string(data)
+	switch {
+	case !text:
+		payload = fmt.Sprintf("[binary %d bytes] %s", len(data), base64.StdEncoding.EncodeToString(data))
+	case op == websocket.OpPing || op == websocket.OpPong:
+		payload = fmt.Sprintf("[%s] %s", op, payload)
+	}
+	fmt.Fprintf(l.w, "%s %s %s\n", now.Format("15:04:05.000"), dir, payload)
+}
+
+// wsToHTTP maps ws:// and wss:// to the http(s) URL of the handshake.
+func wsToHTTP(u string) string {
+	lower := strings.ToLower(u)
+	switch {
+	case strings.HasPrefix(lower, "ws://"):
+		return "http://" + u[len("ws://"):]
+	case strings.HasPrefix(lower, "wss://"):
+		return "https://" + u[len("wss://"):]
+	}
+	return u
+}
+
+// httpToWS is the reverse of wsToHTTP, for display.
+func httpToWS(u string) string {
+	lower := strings.ToLower(u)
+	switch {
+	case strings.HasPrefix(lower, "http://"):
+		return "ws://" + u[len("http://"):]
+	case strings.HasPrefix(lower, "https://"):
+		return "wss://" + u[len("https://"):]
+	}
+	return u
+}
@@ -17,16 +17,32 @@ type Profile struct {
 	User     string `json:"user,omitempty"`
 	Pass     string `json:"pass,omitempty"`
 	Token    string `json:"token,omitempty"`
+
+	// OpenAPI is the path of the OpenAPI document describing the API.
+	OpenAPI string `json:"openapi,omitempty"`
+}
+
+// Request represents a saved named request. Path, header values and Body
+// may contain {{name}} placeholders filled in at run time.
+type Request struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method,omitempty"`
+	Path    string            `json:"path"`
+	Profile string            `json:"profile,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"` // JSON body template
 }
 
 // Config is the root config file structure.
 type Config struct {
 	Profiles map[string]Profile `json:"profiles"`
+	Requests map[string]Request `json:"requests,omitempty"`
 }
 
 func defaultConfig() *Config {
 	return &Config{
 		Profiles: make(map[string]Profile),
+		Requests: make(map[string]Request),
 	}
 }
 
@@ -66,6 +82,9 @@ func Load() (*Config, error) {
 	if cfg.Profiles == nil {
 		cfg.Profiles = make(map[string]Profile)
 	}
+	if cfg.Requests == nil {
+		cfg.Requests = make(map[string]Request)
+	}
 	return &cfg, nil
 }
 
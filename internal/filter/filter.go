@@ -0,0 +1,64 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/jsonpath"
+)
+
+// Filter is a compiled --filter expression.
+type Filter struct {
+	expr string
+	run  func(doc interface{}) ([]interface{}, error)
+}
+
+// Compile compiles a filter expression. Expressions starting with "$" are
+// JSONPath; everything else is treated as a jq subset (see jq.go).
+func Compile(expr string) (*Filter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty filter")
+	}
+
+	if strings.HasPrefix(expr, "$") {
+		// Validate syntax up front
+		if _, err := jsonpath.Query(nil, expr); err != nil {
+			return nil, err
+		}
+		definite := jsonpath.IsDefinite(expr)
+		return &Filter{expr: expr, run: func(doc interface{}) ([]interface{}, error) {
+			matches, err := jsonpath.Query(doc, expr)
+			if err != nil {
+				return nil, err
+			}
+			if !definite {
+				// JSONPath convention: a node list is returned as an array
+				if matches == nil {
+					matches = []interface{}{}
+				}
+				return []interface{}{matches}, nil
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s: no match", expr)
+			}
+			return matches, nil
+		}}, nil
+	}
+
+	fn, err := parseJQ(expr)
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", expr, err)
+	}
+	return &Filter{expr: expr, run: fn}, nil
+}
+
+// Apply runs the filter against a decoded JSON document and returns its
+// outputs. JSONPath filters return exactly one output; jq filters may
+// return any number (e.g. ".items[].id").
+func (f *Filter) Apply(doc interface{}) ([]interface{}, error) {
+	return f.run(doc)
+}
+
+// String returns the source expression.
+func (f *Filter) String() string { return f.expr }
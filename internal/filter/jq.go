@@ -0,0 +1,1252 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The jq subset supported here:
+//
+//	.  .a  .a.b  ."a b"  .[0]  .[-1]  .["a"]  .[2:5]  .[]  .a[]?
+//	a | b      pipe
+//	a, b       multiple outputs
+//	[ ... ]    collect outputs into an array
+//	{a, b: .x, "c": .y}  object construction
+//	== != < <= > >=  and  or  + -
+//	literals: numbers, "strings", true, false, null
+//	functions: length keys values map(f) select(f) first last first(f)
+//	  sort sort_by(f) unique reverse min max add flatten to_entries
+//	  from_entries type tostring tonumber has(k) join(s) split(s)
+//	  test(re) ascii_downcase ascii_upcase not empty
+type jqFunc func(in interface{}) ([]interface{}, error)
+
+func parseJQ(expr string) (jqFunc, error) {
+	toks, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &jqParser{toks: toks}
+	fn, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tEOF {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	return fn, nil
+}
+
+// --- tokenizer ---
+
+type tokKind int
+
+const (
+	tEOF tokKind = iota
+	tDot
+	tField
+	tIdent
+	tString
+	tNumber
+	tPunct
+	tOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+	str  string  // tField name / tString value
+	num  float64 // tNumber value
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.':
+			if i+1 < len(s) && isIdentStart(s[i+1]) {
+				j := i + 1
+				for j < len(s) && isIdentChar(s[j]) {
+					j++
+				}
+				toks = append(toks, token{kind: tField, text: s[i:j], str: s[i+1 : j]})
+				i = j
+			} else if i+1 < len(s) && s[i+1] == '"' {
+				str, n, err := readString(s[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				toks = append(toks, token{kind: tField, text: s[i : i+1+n], str: str})
+				i += 1 + n
+			} else {
+				toks = append(toks, token{kind: tDot, text: "."})
+				i++
+			}
+		case c == '"':
+			str, n, err := readString(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tString, text: s[i : i+n], str: str})
+			i += n
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9' && valueAllowed(toks):
+			j := i + 1
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.' || s[j] == 'e' || s[j] == 'E') {
+				j++
+			}
+			f, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", s[i:j])
+			}
+			toks = append(toks, token{kind: tNumber, text: s[i:j], num: f})
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+			toks = append(toks, token{kind: tIdent, text: s[i:j]})
+			i = j
+		case strings.ContainsRune("[](){}|,:?;", rune(c)):
+			toks = append(toks, token{kind: tPunct, text: string(c)})
+			i++
+		default:
+			if i+1 < len(s) {
+				two := s[i : i+2]
+				if two == "==" || two == "!=" || two == "<=" || two == ">=" {
+					toks = append(toks, token{kind: tOp, text: two})
+					i += 2
+					continue
+				}
+			}
+			if c == '<' || c == '>' || c == '+' || c == '-' {
+				toks = append(toks, token{kind: tOp, text: string(c)})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return append(toks, token{kind: tEOF}), nil
+}
+
+// valueAllowed reports whether a '-' at this point starts a negative number
+// rather than being the minus operator.
+func valueAllowed(toks []token) bool {
+	if len(toks) == 0 {
+		return true
+	}
+	last := toks[len(toks)-1]
+	return last.kind == tOp || last.kind == tPunct && strings.Contains("[(|,:;{", last.text)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+func readString(s string) (string, int, error) {
+	for j := 1; j < len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if s[j] == '"' {
+			str, err := strconv.Unquote(s[:j+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid string %s", s[:j+1])
+			}
+			return str, j + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// --- parser ---
+
+type jqParser struct {
+	toks []token
+	pos  int
+}
+
+func (p *jqParser) peek() token { return p.toks[p.pos] }
+
+func (p *jqParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *jqParser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tPunct && t.text == s
+}
+
+func (p *jqParser) expect(s string) error {
+	if !p.isPunct(s) {
+		return fmt.Errorf("expected %q, got %q", s, p.peek().text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *jqParser) parsePipe() (jqFunc, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("|") {
+		p.next()
+		right, err := p.parseComma()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = func(in interface{}) ([]interface{}, error) {
+			mid, err := l(in)
+			if err != nil {
+				return nil, err
+			}
+			var out []interface{}
+			for _, m := range mid {
+				res, err := r(m)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, res...)
+			}
+			return out, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseComma() (jqFunc, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct(",") {
+		p.next()
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = func(in interface{}) ([]interface{}, error) {
+			a, err := l(in)
+			if err != nil {
+				return nil, err
+			}
+			b, err := r(in)
+			if err != nil {
+				return nil, err
+			}
+			return append(a, b...), nil
+		}
+	}
+	return left, nil
+}
+
+func (p *jqParser) parseOr() (jqFunc, error) {
+	return p.parseBinary(func() (jqFunc, error) { return p.parseAnd() }, "or")
+}
+
+func (p *jqParser) parseAnd() (jqFunc, error) {
+	return p.parseBinary(func() (jqFunc, error) { return p.parseCompare() }, "and")
+}
+
+func (p *jqParser) parseCompare() (jqFunc, error) {
+	return p.parseBinary(func() (jqFunc, error) { return p.parseAdditive() }, "==", "!=", "<", "<=", ">", ">=")
+}
+
+func (p *jqParser) parseAdditive() (jqFunc, error) {
+	return p.parseBinary(func() (jqFunc, error) { return p.parsePostfix() }, "+", "-")
+}
+
+// parseBinary parses left-associative operators; each side is evaluated
+// against the same input and combined pairwise.
+func (p *jqParser) parseBinary(operand func() (jqFunc, error), ops ...string) (jqFunc, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		op := ""
+		for _, o := range ops {
+			if (t.kind == tOp || t.kind == tIdent) && t.text == o {
+				op = o
+			}
+		}
+		if op == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l, r := left, right
+		left = func(in interface{}) ([]interface{}, error) {
+			a, err := l(in)
+			if err != nil {
+				return nil, err
+			}
+			b, err := r(in)
+			if err != nil {
+				return nil, err
+			}
+			var out []interface{}
+			for _, x := range a {
+				for _, y := range b {
+					v, err := binaryOp(op, x, y)
+					if err != nil {
+						return nil, err
+					}
+					out = append(out, v)
+				}
+			}
+			return out, nil
+		}
+	}
+}
+
+func (p *jqParser) parsePostfix() (jqFunc, error) {
+	fn, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	// "?" suppresses errors of the step right before it only
+	var (
+		prev = identity
+		last = fn
+	)
+	for {
+		t := p.peek()
+		var suffix jqFunc
+		switch {
+		case t.kind == tField:
+			p.next()
+			suffix = fieldAccess(t.str)
+		case t.kind == tPunct && t.text == "[":
+			p.next()
+			if suffix, err = p.parseBracketSuffix(); err != nil {
+				return nil, err
+			}
+		case t.kind == tPunct && t.text == "?":
+			p.next()
+			inner := last
+			last = func(in interface{}) ([]interface{}, error) {
+				out, err := inner(in)
+				if err != nil {
+					return nil, nil
+				}
+				return out, nil
+			}
+			fn = chain(prev, last)
+			continue
+		default:
+			return fn, nil
+		}
+		prev, last = fn, suffix
+		fn = chain(fn, suffix)
+	}
+}
+
+func chain(first, second jqFunc) jqFunc {
+	return func(in interface{}) ([]interface{}, error) {
+		mid, err := first(in)
+		if err != nil {
+			return nil, err
+		}
+		var out []interface{}
+		for _, m := range mid {
+			res, err := second(m)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, res...)
+		}
+		return out, nil
+	}
+}
+
+// parseBracketSuffix parses what follows "[": "]", "N]", "\"key\"]",
+// "A:B]".
+func (p *jqParser) parseBracketSuffix() (jqFunc, error) {
+	if p.isPunct("]") {
+		p.next()
+		return iterate, nil
+	}
+
+	readBound := func() (*float64, error) {
+		t := p.peek()
+		if t.kind == tNumber {
+			p.next()
+			n := t.num
+			return &n, nil
+		}
+		return nil, nil
+	}
+
+	t := p.peek()
+	if t.kind == tString {
+		p.next()
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return fieldAccess(t.str), nil
+	}
+
+	start, err := readBound()
+	if err != nil {
+		return nil, err
+	}
+	if p.isPunct(":") {
+		p.next()
+		end, err := readBound()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return slice(start, end), nil
+	}
+	if start == nil {
+		return nil, fmt.Errorf("unsupported index expression %q", p.peek().text)
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return index(int(*start)), nil
+}
+
+func (p *jqParser) parsePrimary() (jqFunc, error) {
+	t := p.next()
+	switch t.kind {
+	case tDot:
+		// ".[...]" is handled by the postfix loop
+		return identity, nil
+	case tField:
+		return fieldAccess(t.str), nil
+	case tString:
+		return literal(t.str), nil
+	case tNumber:
+		return literal(t.num), nil
+	case tPunct:
+		switch t.text {
+		case "(":
+			fn, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return fn, p.expect(")")
+		case "[":
+			if p.isPunct("]") {
+				p.next()
+				return literal([]interface{}{}), nil
+			}
+			fn, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			return func(in interface{}) ([]interface{}, error) {
+				out, err := fn(in)
+				if err != nil {
+					return nil, err
+				}
+				if out == nil {
+					out = []interface{}{}
+				}
+				return []interface{}{out}, nil
+			}, nil
+		case "{":
+			return p.parseObject()
+		}
+	case tIdent:
+		return p.parseFunction(t.text)
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+func (p *jqParser) parseObject() (jqFunc, error) {
+	type entry struct {
+		key string
+		val jqFunc
+	}
+	var entries []entry
+	for !p.isPunct("}") {
+		t := p.next()
+		var key string
+		switch t.kind {
+		case tIdent, tString:
+			key = t.text
+			if t.kind == tString {
+				key = t.str
+			}
+		default:
+			return nil, fmt.Errorf("expected object key, got %q", t.text)
+		}
+		val := fieldAccess(key)
+		if p.isPunct(":") {
+			p.next()
+			v, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			val = v
+		}
+		entries = append(entries, entry{key: key, val: val})
+		if p.isPunct(",") {
+			p.next()
+			continue
+		}
+		if !p.isPunct("}") {
+			return nil, fmt.Errorf("expected ',' or '}' in object, got %q", p.peek().text)
+		}
+	}
+	p.next()
+
+	return func(in interface{}) ([]interface{}, error) {
+		results := []map[string]interface{}{{}}
+		for _, e := range entries {
+			vals, err := e.val(in)
+			if err != nil {
+				return nil, err
+			}
+			var next []map[string]interface{}
+			for _, partial := range results {
+				for _, v := range vals {
+					obj := make(map[string]interface{}, len(partial)+1)
+					for k, pv := range partial {
+						obj[k] = pv
+					}
+					obj[e.key] = v
+					next = append(next, obj)
+				}
+			}
+			results = next
+		}
+		out := make([]interface{}, len(results))
+		for i, r := range results {
+			out[i] = r
+		}
+		return out, nil
+	}, nil
+}
+
+func (p *jqParser) parseArgs() ([]jqFunc, error) {
+	if !p.isPunct("(") {
+		return nil, nil
+	}
+	p.next()
+	var args []jqFunc
+	for {
+		a, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, a)
+		if p.isPunct(";") {
+			p.next()
+			continue
+		}
+		return args, p.expect(")")
+	}
+}
+
+func (p *jqParser) parseFunction(name string) (jqFunc, error) {
+	switch name {
+	case "true":
+		return literal(true), nil
+	case "false":
+		return literal(false), nil
+	case "null":
+		return literal(nil), nil
+	}
+
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+	want := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("%s expects %d argument(s)", name, n)
+		}
+		return nil
+	}
+
+	// Functions taking a filter argument
+	switch name {
+	case "map", "select", "sort_by":
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		f := args[0]
+		switch name {
+		case "map":
+			return chain(func(in interface{}) ([]interface{}, error) {
+				items, err := iterate(in)
+				if err != nil {
+					return nil, err
+				}
+				var out []interface{}
+				for _, it := range items {
+					res, err := f(it)
+					if err != nil {
+						return nil, err
+					}
+					out = append(out, res...)
+				}
+				if out == nil {
+					out = []interface{}{}
+				}
+				return []interface{}{out}, nil
+			}, identity), nil
+		case "select":
+			return func(in interface{}) ([]interface{}, error) {
+				res, err := f(in)
+				if err != nil {
+					return nil, err
+				}
+				for _, r := range res {
+					if truthy(r) {
+						return []interface{}{in}, nil
+					}
+				}
+				return nil, nil
+			}, nil
+		default: // sort_by
+			return func(in interface{}) ([]interface{}, error) {
+				arr, ok := in.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("sort_by: cannot sort %s", typeName(in))
+				}
+				keys := make([]interface{}, len(arr))
+				for i, it := range arr {
+					k, err := f(it)
+					if err != nil {
+						return nil, err
+					}
+					keys[i] = interface{}(k)
+				}
+				idx := make([]int, len(arr))
+				for i := range idx {
+					idx[i] = i
+				}
+				sort.SliceStable(idx, func(a, b int) bool { return compareValues(keys[idx[a]], keys[idx[b]]) < 0 })
+				out := make([]interface{}, len(arr))
+				for i, j := range idx {
+					out[i] = arr[j]
+				}
+				return []interface{}{out}, nil
+			}, nil
+		}
+	case "first", "last":
+		if len(args) == 1 {
+			f := args[0]
+			return func(in interface{}) ([]interface{}, error) {
+				res, err := f(in)
+				if err != nil || len(res) == 0 {
+					return nil, err
+				}
+				if name == "first" {
+					return res[:1], nil
+				}
+				return res[len(res)-1:], nil
+			}, nil
+		}
+		if name == "first" {
+			return index(0), want(0)
+		}
+		return index(-1), want(0)
+	case "has", "join", "split", "test":
+		if err := want(1); err != nil {
+			return nil, err
+		}
+		f := args[0]
+		return func(in interface{}) ([]interface{}, error) {
+			argVals, err := f(in)
+			if err != nil {
+				return nil, err
+			}
+			var out []interface{}
+			for _, a := range argVals {
+				v, err := stringArgFunc(name, in, a)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, v)
+			}
+			return out, nil
+		}, nil
+	}
+
+	if err := want(0); err != nil {
+		return nil, err
+	}
+	fn, ok := simpleFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	return func(in interface{}) ([]interface{}, error) {
+		v, err := fn(in)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if name == "empty" {
+			return nil, nil
+		}
+		return []interface{}{v}, nil
+	}, nil
+}
+
+// --- evaluation helpers ---
+
+func identity(in interface{}) ([]interface{}, error) { return []interface{}{in}, nil }
+
+func literal(v interface{}) jqFunc {
+	return func(interface{}) ([]interface{}, error) { return []interface{}{v}, nil }
+}
+
+func fieldAccess(name string) jqFunc {
+	return func(in interface{}) ([]interface{}, error) {
+		switch v := in.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case map[string]interface{}:
+			return []interface{}{v[name]}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with %q", typeName(in), name)
+	}
+}
+
+func index(i int) jqFunc {
+	return func(in interface{}) ([]interface{}, error) {
+		switch v := in.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case []interface{}:
+			j := i
+			if j < 0 {
+				j += len(v)
+			}
+			if j < 0 || j >= len(v) {
+				return []interface{}{nil}, nil
+			}
+			return []interface{}{v[j]}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with number", typeName(in))
+	}
+}
+
+func slice(start, end *float64) jqFunc {
+	return func(in interface{}) ([]interface{}, error) {
+		var n int
+		switch v := in.(type) {
+		case nil:
+			return []interface{}{nil}, nil
+		case []interface{}:
+			n = len(v)
+		case string:
+			n = utf8.RuneCountInString(v)
+		default:
+			return nil, fmt.Errorf("cannot slice %s", typeName(in))
+		}
+		from, to := 0, n
+		if start != nil {
+			from = clamp(int(*start), n)
+		}
+		if end != nil {
+			to = clamp(int(*end), n)
+		}
+		if to < from {
+			to = from
+		}
+		if s, ok := in.(string); ok {
+			return []interface{}{string([]rune(s)[from:to])}, nil
+		}
+		return []interface{}{append([]interface{}{}, in.([]interface{})[from:to]...)}, nil
+	}
+}
+
+func clamp(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+func iterate(in interface{}) ([]interface{}, error) {
+	switch v := in.(type) {
+	case []interface{}:
+		return append([]interface{}(nil), v...), nil
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		out := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, v[k])
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", typeName(in))
+}
+
+func truthy(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return true
+}
+
+func binaryOp(op string, a, b interface{}) (interface{}, error) {
+	switch op {
+	case "and":
+		return truthy(a) && truthy(b), nil
+	case "or":
+		return truthy(a) || truthy(b), nil
+	case "==":
+		return compareValues(a, b) == 0, nil
+	case "!=":
+		return compareValues(a, b) != 0, nil
+	case "<":
+		return compareValues(a, b) < 0, nil
+	case "<=":
+		return compareValues(a, b) <= 0, nil
+	case ">":
+		return compareValues(a, b) > 0, nil
+	case ">=":
+		return compareValues(a, b) >= 0, nil
+	case "+":
+		switch x := a.(type) {
+		case nil:
+			return b, nil
+		case float64:
+			if y, ok := b.(float64); ok {
+				return x + y, nil
+			}
+		case string:
+			if y, ok := b.(string); ok {
+				return x + y, nil
+			}
+		case []interface{}:
+			if y, ok := b.([]interface{}); ok {
+				return append(append([]interface{}{}, x...), y...), nil
+			}
+		case map[string]interface{}:
+			if y, ok := b.(map[string]interface{}); ok {
+				out := make(map[string]interface{}, len(x)+len(y))
+				for k, v := range x {
+					out[k] = v
+				}
+				for k, v := range y {
+					out[k] = v
+				}
+				return out, nil
+			}
+		}
+		if b == nil {
+			return a, nil
+		}
+	case "-":
+		x, ok1 := a.(float64)
+		y, ok2 := b.(float64)
+		if ok1 && ok2 {
+			return x - y, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot apply %s to %s and %s", op, typeName(a), typeName(b))
+}
+
+var simpleFuncs = map[string]func(interface{}) (interface{}, error){
+	"empty": func(interface{}) (interface{}, error) { return nil, nil },
+	"not":   func(in interface{}) (interface{}, error) { return !truthy(in), nil },
+	"type":  func(in interface{}) (interface{}, error) { return typeName(in), nil },
+	"length": func(in interface{}) (interface{}, error) {
+		switch v := in.(type) {
+		case nil:
+			return 0.0, nil
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		case float64:
+			return math.Abs(v), nil
+		}
+		return nil, fmt.Errorf("%s has no length", typeName(in))
+	},
+	"keys": func(in interface{}) (interface{}, error) {
+		switch v := in.(type) {
+		case map[string]interface{}:
+			out := []interface{}{}
+			for _, k := range sortedKeys(v) {
+				out = append(out, k)
+			}
+			return out, nil
+		case []interface{}:
+			out := make([]interface{}, len(v))
+			for i := range v {
+				out[i] = float64(i)
+			}
+			return out, nil
+		}
+		return nil, fmt.Errorf("%s has no keys", typeName(in))
+	},
+	"values": func(in interface{}) (interface{}, error) {
+		items, err := iterate(in)
+		if err != nil {
+			return nil, err
+		}
+		if items == nil {
+			items = []interface{}{}
+		}
+		return items, nil
+	},
+	"sort": func(in interface{}) (interface{}, error) {
+		arr, err := asArray(in)
+		if err != nil {
+			return nil, err
+		}
+		out := append([]interface{}{}, arr...)
+		sort.SliceStable(out, func(i, j int) bool { return compareValues(out[i], out[j]) < 0 })
+		return out, nil
+	},
+	"unique": func(in interface{}) (interface{}, error) {
+		arr, err := asArray(in)
+		if err != nil {
+			return nil, err
+		}
+		sorted := append([]interface{}{}, arr...)
+		sort.SliceStable(sorted, func(i, j int) bool { return compareValues(sorted[i], sorted[j]) < 0 })
+		out := []interface{}{}
+		for i, v := range sorted {
+			if i == 0 || compareValues(v, sorted[i-1]) != 0 {
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	},
+	"reverse": func(in interface{}) (interface{}, error) {
+		arr, err := asArray(in)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]interface{}, len(arr))
+		for i, v := range arr {
+			out[len(arr)-1-i] = v
+		}
+		return out, nil
+	},
+	"min": func(in interface{}) (interface{}, error) { return extreme(in, -1) },
+	"max": func(in interface{}) (interface{}, error) { return extreme(in, 1) },
+	"add": func(in interface{}) (interface{}, error) {
+		arr, err := asArray(in)
+		if err != nil {
+			return nil, err
+		}
+		var acc interface{}
+		for _, v := range arr {
+			if acc, err = binaryOp("+", acc, v); err != nil {
+				return nil, err
+			}
+		}
+		return acc, nil
+	},
+	"flatten": func(in interface{}) (interface{}, error) {
+		arr, err := asArray(in)
+		if err != nil {
+			return nil, err
+		}
+		return flatten(arr), nil
+	},
+	"to_entries": func(in interface{}) (interface{}, error) {
+		m, ok := in.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s has no entries", typeName(in))
+		}
+		out := []interface{}{}
+		for _, k := range sortedKeys(m) {
+			out = append(out, map[string]interface{}{"key": k, "value": m[k]})
+		}
+		return out, nil
+	},
+	"from_entries": func(in interface{}) (interface{}, error) {
+		arr, err := asArray(in)
+		if err != nil {
+			return nil, err
+		}
+		out := map[string]interface{}{}
+		for _, e := range arr {
+			m, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("entry is %s, not object", typeName(e))
+			}
+			k := m["key"]
+			if k == nil {
+				k = m["name"]
+			}
+			out[fmt.Sprint(k)] = m["value"]
+		}
+		return out, nil
+	},
+	"tostring": func(in interface{}) (interface{}, error) {
+		if s, ok := in.(string); ok {
+			return s, nil
+		}
+		data, err := json.Marshal(in)
+		return string(data), err
+	},
+	"tonumber": func(in interface{}) (interface{}, error) {
+		switch v := in.(type) {
+		case float64:
+			return v, nil
+		case string:
+			return strconv.ParseFloat(strings.TrimSpace(v), 64)
+		}
+		return nil, fmt.Errorf("cannot parse %s as number", typeName(in))
+	},
+	"ascii_downcase": func(in interface{}) (interface{}, error) {
+		s, ok := in.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a string", typeName(in))
+		}
+		return strings.ToLower(s), nil
+	},
+	"ascii_upcase": func(in interface{}) (interface{}, error) {
+		s, ok := in.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a string", typeName(in))
+		}
+		return strings.ToUpper(s), nil
+	},
+}
+
+func stringArgFunc(name string, in, arg interface{}) (interface{}, error) {
+	switch name {
+	case "has":
+		switch v := in.(type) {
+		case map[string]interface{}:
+			k, ok := arg.(string)
+			if !ok {
+				return nil, fmt.Errorf("has: object key must be a string")
+			}
+			_, found := v[k]
+			return found, nil
+		case []interface{}:
+			n, ok := arg.(float64)
+			if !ok {
+				return nil, fmt.Errorf("has: array index must be a number")
+			}
+			return n >= 0 && int(n) < len(v), nil
+		}
+		return nil, fmt.Errorf("has: cannot check %s", typeName(in))
+	case "join":
+		arr, err := asArray(in)
+		if err != nil {
+			return nil, err
+		}
+		sep, _ := arg.(string)
+		parts := make([]string, len(arr))
+		for i, v := range arr {
+			if v == nil {
+				continue
+			}
+			if s, ok := v.(string); ok {
+				parts[i] = s
+			} else {
+				data, _ := json.Marshal(v)
+				parts[i] = string(data)
+			}
+		}
+		return strings.Join(parts, sep), nil
+	case "split":
+		s, ok1 := in.(string)
+		sep, ok2 := arg.(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("split: input and separator must be strings")
+		}
+		out := []interface{}{}
+		for _, part := range strings.Split(s, sep) {
+			out = append(out, part)
+		}
+		return out, nil
+	default: // test
+		s, ok1 := in.(string)
+		pattern, ok2 := arg.(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("test: input and pattern must be strings")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(s), nil
+	}
+}
+
+func asArray(in interface{}) ([]interface{}, error) {
+	arr, ok := in.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", typeName(in))
+	}
+	return arr, nil
+}
+
+func extreme(in interface{}, sign int) (interface{}, error) {
+	arr, err := asArray(in)
+	if err != nil {
+		return nil, err
+	}
+	var best interface{}
+	for i, v := range arr {
+		if i == 0 || compareValues(v, best)*sign > 0 {
+			best = v
+		}
+	}
+	return best, nil
+}
+
+func flatten(arr []interface{}) []interface{} {
+	out := []interface{}{}
+	for _, v := range arr {
+		if inner, ok := v.([]interface{}); ok {
+			out = append(out, flatten(inner)...)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// compareValues orders JSON values the way jq does:
+// null < false < true < numbers < strings < arrays < objects.
+func compareValues(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch x := a.(type) {
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		}
+		return 1
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(x, b.(string))
+	case []interface{}:
+		y := b.([]interface{})
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if c := compareValues(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+		return len(x) - len(y)
+	case map[string]interface{}:
+		y := b.(map[string]interface{})
+		kx, ky := sortedKeys(x), sortedKeys(y)
+		ax, ay := make([]interface{}, len(kx)), make([]interface{}, len(ky))
+		for i, k := range kx {
+			ax[i] = k
+		}
+		for i, k := range ky {
+			ay[i] = k
+		}
+		if c := compareValues(ax, ay); c != 0 {
+			return c
+		}
+		for _, k := range kx {
+			if c := compareValues(x[k], y[k]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func typeRank(v interface{}) int {
+	switch x := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if x {
+			return 2
+		}
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	}
+	return 6
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
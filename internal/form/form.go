@@ -0,0 +1,282 @@
+// Package form streams multipart/form-data bodies: fields and files are
+// written while the request is sent, so uploads never sit in memory.
+package form
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Field is a plain form value.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// File is a file part.
+type File struct {
+	Field       string
+	Path        string
+	FileName    string // defaults to the base name of Path
+	ContentType string // defaults to a guess from the extension
+
+	size int64
+}
+
+// ParseField parses "name=value".
+func ParseField(s string) (Field, error) {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return Field{}, fmt.Errorf("invalid form field %q, expected 'name=value'", s)
+	}
+	return Field{Name: strings.TrimSpace(name), Value: value}, nil
+}
+
+// ParseFile parses "name=@path[;type=mime][;filename=name]"; the @ is
+// optional.
+func ParseFile(s string) (File, error) {
+	name, rest, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" || rest == "" {
+		return File{}, fmt.Errorf("invalid file %q, expected 'name=@path[;type=mime]'", s)
+	}
+	parts := strings.Split(strings.TrimPrefix(rest, "@"), ";")
+	f := File{Field: strings.TrimSpace(name), Path: parts[0]}
+	for _, p := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+		switch strings.ToLower(key) {
+		case "type":
+			f.ContentType = value
+		case "filename":
+			f.FileName = value
+		default:
+			return File{}, fmt.Errorf("file %q: unknown option %q (want type= or filename=)", s, key)
+		}
+	}
+	if f.Path == "" {
+		return File{}, fmt.Errorf("file %q: path is empty", s)
+	}
+	return f, nil
+}
+
+// Body is a multipart body that can be opened once per attempt.
+type Body struct {
+	fields   []Field
+	files    []File
+	boundary string
+	size     int64
+}
+
+// New checks that all files exist and computes the exact body size, so the
+// request can carry a Content-Length instead of being sent chunked.
+func New(fields []Field, files []File) (*Body, error) {
+	b := &Body{fields: fields, boundary: randomBoundary()}
+	for _, f := range files {
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", f.Field, err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("file %s: %s is a directory", f.Field, f.Path)
+		}
+		if f.FileName == "" {
+			f.FileName = filepath.Base(f.Path)
+		}
+		if f.ContentType == "" {
+			f.ContentType = mime.TypeByExtension(filepath.Ext(f.Path))
+			if f.ContentType == "" {
+				f.ContentType = "application/octet-stream"
+			}
+		}
+		f.size = info.Size()
+		b.files = append(b.files, f)
+	}
+
+	// The multipart framing does not depend on the file contents, so write
+	// it once with empty files and add the file sizes.
+	var cw countingWriter
+	if err := b.write(&cw, false); err != nil {
+		return nil, err
+	}
+	b.size = cw.n
+	for _, f := range b.files {
+		b.size += f.size
+	}
+	return b, nil
+}
+
+// ContentType is the Content-Type header value, including the boundary.
+func (b *Body) ContentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// Boundary is the multipart boundary of the body.
+func (b *Body) Boundary() string { return b.boundary }
+
+// Size is the exact length of the encoded body.
+func (b *Body) Size() int64 { return b.size }
+
+// FileSize is the total size of all file parts.
+func (b *Body) FileSize() int64 {
+	var n int64
+	for _, f := range b.files {
+		n += f.size
+	}
+	return n
+}
+
+// Summary lists the parts for request previews.
+func (b *Body) Summary() string {
+	var lines []string
+	for _, f := range b.fields {
+		lines = append(lines, fmt.Sprintf("  %s = %s", f.Name, f.Value))
+	}
+	for _, f := range b.files {
+		lines = append(lines, fmt.Sprintf("  %s = @%s (%s, %s, %s)", f.Field, f.Path, f.FileName, f.ContentType, FormatBytes(f.size)))
+	}
+	return fmt.Sprintf("multipart/form-data, %s\n%s", FormatBytes(b.size), strings.Join(lines, "\n"))
+}
+
+// Open returns a reader producing the body. Encoding starts on the first
+// Read, so an unread body (e.g. a request preview) costs nothing. progress,
+// if not nil, receives the number of bytes produced so far.
+func (b *Body) Open(progress func(sent int64)) io.ReadCloser {
+	return &lazyPipe{start: func(w *io.PipeWriter) {
+		var out io.Writer = w
+		if progress != nil {
+			out = &progressWriter{w: w, fn: progress}
+		}
+		w.CloseWithError(b.write(out, true))
+	}}
+}
+
+// write encodes the body; without withContent file parts are left empty.
+func (b *Body) write(w io.Writer, withContent bool) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+	for _, f := range b.fields {
+		if err := mw.WriteField(f.Name, f.Value); err != nil {
+			return err
+		}
+	}
+	for _, f := range b.files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(f.Field), escapeQuotes(f.FileName)))
+		h.Set("Content-Type", f.ContentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if !withContent {
+			continue
+		}
+		if err := copyFile(part, f); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+func copyFile(w io.Writer, f File) error {
+	in, err := os.Open(f.Path)
+	if err != nil {
+		return fmt.Errorf("file %s: %w", f.Field, err)
+	}
+	defer in.Close()
+	n, err := io.Copy(w, in)
+	if err != nil {
+		return fmt.Errorf("file %s: %w", f.Field, err)
+	}
+	if n != f.size {
+		return fmt.Errorf("file %s: %s changed size while uploading", f.Field, f.Path)
+	}
+	return nil
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string { return quoteEscaper.Replace(s) }
+
+func randomBoundary() string {
+	var buf [24]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%x", buf[:])
+}
+
+// lazyPipe starts its writer goroutine on the first Read.
+type lazyPipe struct {
+	start func(w *io.PipeWriter)
+	once  sync.Once
+	r     *io.PipeReader
+}
+
+func (p *lazyPipe) init() {
+	p.once.Do(func() {
+		r, w := io.Pipe()
+		p.r = r
+		go p.start(w)
+	})
+}
+
+func (p *lazyPipe) Read(buf []byte) (int, error) {
+	p.init()
+	if p.r == nil {
+		return 0, io.ErrClosedPipe
+	}
+	return p.r.Read(buf)
+}
+
+// Close stops the writer; a body that was never read is not started.
+func (p *lazyPipe) Close() error {
+	started := true
+	p.once.Do(func() { started = false })
+	if !started || p.r == nil {
+		return nil
+	}
+	return p.r.Close()
+}
+
+type countingWriter struct{ n int64 }
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+type progressWriter struct {
+	w  io.Writer
+	n  int64
+	fn func(int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.n += int64(n)
+	p.fn(p.n)
+	return n, err
+}
+
+// FormatBytes prints a size like "4.2 MB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
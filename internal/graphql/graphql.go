@@ -0,0 +1,101 @@
+// Package graphql builds GraphQL-over-HTTP requests and reads responses,
+// including Automatic Persisted Queries (APQ) and schema introspection.
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Request is the JSON body of a GraphQL POST.
+type Request struct {
+	Query         string                 `json:"query,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Response is a GraphQL result.
+type Response struct {
+	Data       json.RawMessage        `json:"data,omitempty"`
+	Errors     []Error                `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Error is one entry of errors[].
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Location points into the query document.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (e Error) String() string {
+	var b strings.Builder
+	b.WriteString(e.Message)
+	if len(e.Path) > 0 {
+		parts := make([]string, len(e.Path))
+		for i, p := range e.Path {
+			parts[i] = fmt.Sprint(p)
+		}
+		fmt.Fprintf(&b, " (path: %s)", strings.Join(parts, "."))
+	}
+	for _, l := range e.Locations {
+		fmt.Fprintf(&b, " (line %d:%d)", l.Line, l.Column)
+	}
+	if code, ok := e.Extensions["code"].(string); ok {
+		fmt.Fprintf(&b, " [%s]", code)
+	}
+	return b.String()
+}
+
+// Hash is the sha256 hex digest APQ uses to identify a query.
+func Hash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// PersistedExtension is the "extensions" entry of an APQ request.
+func PersistedExtension(hash string) map[string]interface{} {
+	return map[string]interface{}{
+		"persistedQuery": map[string]interface{}{
+			"version":    1,
+			"sha256Hash": hash,
+		},
+	}
+}
+
+// IsPersistedQueryNotFound reports whether the server does not know the
+// hash yet and wants the full query (APQ registration round).
+func (r *Response) IsPersistedQueryNotFound() bool {
+	for _, e := range r.Errors {
+		if e.Message == "PersistedQueryNotFound" {
+			return true
+		}
+		if code, _ := e.Extensions["code"].(string); code == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+	return false
+}
+
+var operationRe = regexp.MustCompile(`(?m)^\s*(query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)`)
+
+// Operations lists the named operations of a document, in order.
+func Operations(query string) []string {
+	var names []string
+	for _, m := range operationRe.FindAllStringSubmatch(query, -1) {
+		names = append(names, m[2])
+	}
+	return names
+}
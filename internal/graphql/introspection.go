@@ -0,0 +1,147 @@
+package graphql
+
+// IntrospectionQuery fetches everything needed to print the schema as SDL.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      isRepeatable
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  specifiedByURL
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// Schema is the __schema part of an introspection result.
+type Schema struct {
+	QueryType        *TypeName   `json:"queryType"`
+	MutationType     *TypeName   `json:"mutationType"`
+	SubscriptionType *TypeName   `json:"subscriptionType"`
+	Types            []FullType  `json:"types"`
+	Directives       []Directive `json:"directives"`
+}
+
+// TypeName is a named type reference.
+type TypeName struct {
+	Name string `json:"name"`
+}
+
+// FullType describes one schema type.
+type FullType struct {
+	Kind           string       `json:"kind"`
+	Name           string       `json:"name"`
+	Description    *string      `json:"description"`
+	SpecifiedByURL *string      `json:"specifiedByURL"`
+	Fields         []Field      `json:"fields"`
+	InputFields    []InputValue `json:"inputFields"`
+	Interfaces     []TypeRef    `json:"interfaces"`
+	EnumValues     []EnumValue  `json:"enumValues"`
+	PossibleTypes  []TypeRef    `json:"possibleTypes"`
+}
+
+// Field is an object or interface field.
+type Field struct {
+	Name              string       `json:"name"`
+	Description       *string      `json:"description"`
+	Args              []InputValue `json:"args"`
+	Type              TypeRef      `json:"type"`
+	IsDeprecated      bool         `json:"isDeprecated"`
+	DeprecationReason *string      `json:"deprecationReason"`
+}
+
+// InputValue is an argument or input field.
+type InputValue struct {
+	Name         string  `json:"name"`
+	Description  *string `json:"description"`
+	Type         TypeRef `json:"type"`
+	DefaultValue *string `json:"defaultValue"`
+}
+
+// EnumValue is one value of an enum.
+type EnumValue struct {
+	Name              string  `json:"name"`
+	Description       *string `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+// TypeRef is a possibly wrapped (LIST / NON_NULL) type reference.
+type TypeRef struct {
+	Kind   string   `json:"kind"`
+	Name   *string  `json:"name"`
+	OfType *TypeRef `json:"ofType"`
+}
+
+// Directive is a directive definition.
+type Directive struct {
+	Name         string       `json:"name"`
+	Description  *string      `json:"description"`
+	IsRepeatable bool         `json:"isRepeatable"`
+	Locations    []string     `json:"locations"`
+	Args         []InputValue `json:"args"`
+}
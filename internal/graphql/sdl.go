@@ -0,0 +1,237 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// builtinScalars and builtinDirectives are implied by every schema and are
+// left out of the SDL.
+var (
+	builtinScalars    = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+	builtinDirectives = map[string]bool{"skip": true, "include": true, "deprecated": true, "specifiedBy": true, "oneOf": true}
+)
+
+// SchemaSDL converts the data of an introspection response into SDL.
+func SchemaSDL(data json.RawMessage) (string, error) {
+	var wrapper struct {
+		Schema *Schema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return "", fmt.Errorf("decode introspection: %w", err)
+	}
+	if wrapper.Schema == nil {
+		return "", fmt.Errorf("introspection result has no __schema (is introspection disabled?)")
+	}
+	return PrintSchema(wrapper.Schema), nil
+}
+
+// PrintSchema renders s as SDL, types sorted by name.
+func PrintSchema(s *Schema) string {
+	var blocks []string
+
+	if def := schemaDefinition(s); def != "" {
+		blocks = append(blocks, def)
+	}
+
+	dirs := append([]Directive(nil), s.Directives...)
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].Name < dirs[j].Name })
+	for _, d := range dirs {
+		if builtinDirectives[d.Name] {
+			continue
+		}
+		var b strings.Builder
+		writeDescription(&b, d.Description, "")
+		fmt.Fprintf(&b, "directive @%s%s", d.Name, printArgs(d.Args, ""))
+		if d.IsRepeatable {
+			b.WriteString(" repeatable")
+		}
+		fmt.Fprintf(&b, " on %s", strings.Join(d.Locations, " | "))
+		blocks = append(blocks, b.String())
+	}
+
+	types := append([]FullType(nil), s.Types...)
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+	for _, t := range types {
+		if strings.HasPrefix(t.Name, "__") || (t.Kind == "SCALAR" && builtinScalars[t.Name]) {
+			continue
+		}
+		blocks = append(blocks, printType(t))
+	}
+
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// schemaDefinition is only needed when root types use non-default names.
+func schemaDefinition(s *Schema) string {
+	roots := []struct {
+		op   string
+		t    *TypeName
+		want string
+	}{
+		{"query", s.QueryType, "Query"},
+		{"mutation", s.MutationType, "Mutation"},
+		{"subscription", s.SubscriptionType, "Subscription"},
+	}
+	custom := false
+	var lines []string
+	for _, r := range roots {
+		if r.t == nil {
+			continue
+		}
+		if r.t.Name != r.want {
+			custom = true
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", r.op, r.t.Name))
+	}
+	if !custom {
+		return ""
+	}
+	return "schema {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+func printType(t FullType) string {
+	var b strings.Builder
+	writeDescription(&b, t.Description, "")
+
+	switch t.Kind {
+	case "SCALAR":
+		fmt.Fprintf(&b, "scalar %s", t.Name)
+		if t.SpecifiedByURL != nil && *t.SpecifiedByURL != "" {
+			fmt.Fprintf(&b, " @specifiedBy(url: %s)", strconv.Quote(*t.SpecifiedByURL))
+		}
+	case "OBJECT", "INTERFACE":
+		keyword := "type"
+		if t.Kind == "INTERFACE" {
+			keyword = "interface"
+		}
+		fmt.Fprintf(&b, "%s %s", keyword, t.Name)
+		if len(t.Interfaces) > 0 {
+			names := make([]string, len(t.Interfaces))
+			for i, in := range t.Interfaces {
+				names[i] = typeString(in)
+			}
+			fmt.Fprintf(&b, " implements %s", strings.Join(names, " & "))
+		}
+		b.WriteString(" {\n")
+		for i, f := range t.Fields {
+			if i > 0 && f.Description != nil && *f.Description != "" {
+				b.WriteString("\n")
+			}
+			writeDescription(&b, f.Description, "  ")
+			fmt.Fprintf(&b, "  %s%s: %s%s\n", f.Name, printArgs(f.Args, "  "), typeString(f.Type), deprecated(f.IsDeprecated, f.DeprecationReason))
+		}
+		b.WriteString("}")
+	case "UNION":
+		names := make([]string, len(t.PossibleTypes))
+		for i, p := range t.PossibleTypes {
+			names[i] = typeString(p)
+		}
+		fmt.Fprintf(&b, "union %s = %s", t.Name, strings.Join(names, " | "))
+	case "ENUM":
+		fmt.Fprintf(&b, "enum %s {\n", t.Name)
+		for _, v := range t.EnumValues {
+			writeDescription(&b, v.Description, "  ")
+			fmt.Fprintf(&b, "  %s%s\n", v.Name, deprecated(v.IsDeprecated, v.DeprecationReason))
+		}
+		b.WriteString("}")
+	case "INPUT_OBJECT":
+		fmt.Fprintf(&b, "input %s {\n", t.Name)
+		for _, f := range t.InputFields {
+			writeDescription(&b, f.Description, "  ")
+			fmt.Fprintf(&b, "  %s\n", inputValue(f))
+		}
+		b.WriteString("}")
+	default:
+		fmt.Fprintf(&b, "# unknown kind %s for %s", t.Kind, t.Name)
+	}
+	return b.String()
+}
+
+// printArgs renders "(a: Int, b: String = \"x\")", one per line when any
+// argument has a description.
+func printArgs(args []InputValue, indent string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	multiline := false
+	for _, a := range args {
+		if a.Description != nil && *a.Description != "" {
+			multiline = true
+		}
+	}
+	if !multiline {
+		parts := make([]string, len(args))
+		for i, a := range args {
+			parts[i] = inputValue(a)
+		}
+		return "(" + strings.Join(parts, ", ") + ")"
+	}
+	var b strings.Builder
+	b.WriteString("(\n")
+	for _, a := range args {
+		writeDescription(&b, a.Description, indent+"  ")
+		fmt.Fprintf(&b, "%s  %s\n", indent, inputValue(a))
+	}
+	b.WriteString(indent + ")")
+	return b.String()
+}
+
+func inputValue(v InputValue) string {
+	s := v.Name + ": " + typeString(v.Type)
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
+
+func typeString(t TypeRef) string {
+	switch t.Kind {
+	case "NON_NULL":
+		if t.OfType != nil {
+			return typeString(*t.OfType) + "!"
+		}
+	case "LIST":
+		if t.OfType != nil {
+			return "[" + typeString(*t.OfType) + "]"
+		}
+	}
+	if t.Name != nil {
+		return *t.Name
+	}
+	return "?"
+}
+
+func deprecated(is bool, reason *string) string {
+	if !is {
+		return ""
+	}
+	if reason == nil || *reason == "" || *reason == "No longer supported" {
+		return " @deprecated"
+	}
+	return fmt.Sprintf(" @deprecated(reason: %s)", strconv.Quote(*reason))
+}
+
+// writeDescription writes a description as a block string.
+func writeDescription(b *strings.Builder, desc *string, indent string) {
+	if desc == nil || *desc == "" {
+		return
+	}
+	text := strings.ReplaceAll(*desc, `"""`, `\"""`)
+	if !strings.Contains(text, "\n") && len(text) < 70 {
+		fmt.Fprintf(b, "%s\"\"\"%s\"\"\"\n", indent, text)
+		return
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(b, "%s%s\n", indent, line)
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+}
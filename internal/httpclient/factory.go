@@ -3,6 +3,7 @@ package httpclient
 import (
 	"crypto/tls"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -12,11 +13,20 @@ import (
 
 // Config holds all data needed to build a request/client.
 type Config struct {
-	Method        string
-	URL           string
-	Headers       map[string]string
-	Body          []byte
-	Timeout       time.Duration
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    []byte
+	// BodyStream, when set, is used instead of Body for bodies that should
+	// not be held in memory. It is called once per attempt, so retries
+	// send the body again.
+	BodyStream func() (io.ReadCloser, error)
+	// BodySize is the length of BodyStream, or -1 if unknown (chunked).
+	BodySize int64
+	Timeout  time.Duration
+	// HeaderTimeout bounds only the wait for the response headers, after
+	// the body is sent; it suits streamed uploads and downloads.
+	HeaderTimeout time.Duration
 	Auth          auth.Strategy
 	SkipTLSVerify bool
 }
@@ -26,7 +36,13 @@ type Factory struct{}
 
 func (Factory) Build(cfg Config) (*http.Request, *http.Client, error) {
 	var bodyReader io.Reader
-	if len(cfg.Body) > 0 {
+	if cfg.BodyStream != nil {
+		stream, err := cfg.BodyStream()
+		if err != nil {
+			return nil, nil, err
+		}
+		bodyReader = stream
+	} else if len(cfg.Body) > 0 {
 		bodyReader = strings.NewReader(string(cfg.Body))
 	}
 
@@ -34,6 +50,9 @@ func (Factory) Build(cfg Config) (*http.Request, *http.Client, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	if cfg.BodyStream != nil {
+		req.ContentLength = cfg.BodySize
+	}
 
 	for k, v := range cfg.Headers {
 		req.Header.Set(k, v)
@@ -43,7 +62,12 @@ func (Factory) Build(cfg Config) (*http.Request, *http.Client, error) {
 		cfg.Auth.Apply(req)
 	}
 
-	transport := &http.Transport{}
+	transport := &http.Transport{ResponseHeaderTimeout: cfg.HeaderTimeout}
+	if cfg.HeaderTimeout > 0 {
+		// Connecting is bounded too; only sending the body is not
+		transport.DialContext = (&net.Dialer{Timeout: cfg.HeaderTimeout}).DialContext
+		transport.TLSHandshakeTimeout = cfg.HeaderTimeout
+	}
 	if cfg.SkipTLSVerify {
 		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // be careful in prod
 	}
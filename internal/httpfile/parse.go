@@ -0,0 +1,175 @@
+package httpfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Request is one request block of a .http/.rest file. URL, header values
+// and Body are unexpanded: they may still contain {{...}} placeholders.
+type Request struct {
+	Name    string // from "# @name NAME"; may be empty
+	Method  string
+	URL     string
+	Headers []Header
+	Body    string
+	Line    int // line of the request line, for error messages
+}
+
+// Header keeps header order as written in the file.
+type Header struct {
+	Key   string
+	Value string
+}
+
+// File is a parsed .http/.rest file.
+type File struct {
+	Path     string
+	Vars     map[string]string // "@name = value" file variables (unexpanded)
+	Requests []Request
+}
+
+var (
+	methodLine = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|TRACE|CONNECT)\s+(\S+)(\s+HTTP/\S+)?\s*$`)
+	fileVar    = regexp.MustCompile(`^@([A-Za-z_][A-Za-z0-9_.-]*)\s*=\s*(.*)$`)
+	nameAnnot  = regexp.MustCompile(`^(?:#|//)\s*@name\s+(\S+)`)
+)
+
+// ParseFile reads and parses the file at path. Body lines of the form
+// "< ./relative/file" are replaced by the content of that file (relative to
+// the .http file).
+func ParseFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hf, err := Parse(f, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	hf.Path = path
+	return hf, nil
+}
+
+type state int
+
+const (
+	stateStart   state = iota // before the request line
+	stateHeaders              // after the request line, until a blank line
+	stateBody                 // after the blank line, until ###
+)
+
+// Parse parses .http/.rest content. baseDir resolves "< file" body includes.
+func Parse(r io.Reader, baseDir string) (*File, error) {
+	hf := &File{Vars: make(map[string]string)}
+
+	var (
+		cur     *Request
+		st      = stateStart
+		name    string
+		body    []string
+		lineNum int
+	)
+
+	flush := func() {
+		if cur == nil {
+			name = ""
+			return
+		}
+		// Trim trailing blank lines of the body
+		for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+			body = body[:len(body)-1]
+		}
+		cur.Body = strings.Join(body, "\n")
+		hf.Requests = append(hf.Requests, *cur)
+		cur, body, name, st = nil, nil, "", stateStart
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimRight(sc.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		// Text after ### is a separator comment, not a name
+		if strings.HasPrefix(trimmed, "###") {
+			flush()
+			continue
+		}
+
+		switch st {
+		case stateStart:
+			if trimmed == "" {
+				continue
+			}
+			if m := nameAnnot.FindStringSubmatch(trimmed); m != nil {
+				name = m[1]
+				continue
+			}
+			if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+				continue
+			}
+			if m := fileVar.FindStringSubmatch(trimmed); m != nil {
+				hf.Vars[m[1]] = strings.TrimSpace(m[2])
+				continue
+			}
+			cur = &Request{Name: name, Line: lineNum}
+			if m := methodLine.FindStringSubmatch(trimmed); m != nil {
+				cur.Method, cur.URL = m[1], m[2]
+			} else if fields := strings.Fields(trimmed); len(fields) == 1 || len(fields) == 2 && strings.HasPrefix(fields[1], "HTTP/") {
+				// A bare URL means GET
+				cur.Method, cur.URL = "GET", fields[0]
+			} else {
+				return nil, fmt.Errorf("line %d: expected request line, got %q", lineNum, trimmed)
+			}
+			st = stateHeaders
+
+		case stateHeaders:
+			if trimmed == "" {
+				st = stateBody
+				continue
+			}
+			// Multi-line query continuation ("?a=1" / "&b=2")
+			if strings.HasPrefix(trimmed, "?") || strings.HasPrefix(trimmed, "&") {
+				cur.URL += trimmed
+				continue
+			}
+			if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+				continue
+			}
+			key, val, ok := strings.Cut(trimmed, ":")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("line %d: invalid header %q, expected 'Key: Value'", lineNum, trimmed)
+			}
+			cur.Headers = append(cur.Headers, Header{Key: strings.TrimSpace(key), Value: strings.TrimSpace(val)})
+
+		case stateBody:
+			if strings.HasPrefix(trimmed, "< ") {
+				incl := strings.TrimSpace(trimmed[2:])
+				if !filepath.IsAbs(incl) {
+					incl = filepath.Join(baseDir, incl)
+				}
+				data, err := os.ReadFile(incl)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", lineNum, err)
+				}
+				body = append(body, strings.TrimRight(string(data), "\r\n"))
+				continue
+			}
+			body = append(body, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return hf, nil
+}
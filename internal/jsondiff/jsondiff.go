@@ -0,0 +1,323 @@
+// Package jsondiff compares decoded JSON values structurally and masks
+// volatile parts (ids, timestamps) selected by JSON path patterns.
+package jsondiff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the kind of a change.
+type Kind string
+
+const (
+	Added   Kind = "+"
+	Removed Kind = "-"
+	Changed Kind = "~"
+)
+
+// Change is one difference between two values.
+type Change struct {
+	Path string // e.g. $.items[2].name
+	Kind Kind
+	Old  interface{} // unset for Added
+	New  interface{} // unset for Removed
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, show(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, show(c.Old))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Path, show(c.Old), show(c.New))
+}
+
+// Diff lists the differences from old to new. Objects are compared by key
+// and arrays by index; values of different types are one change.
+func Diff(old, new interface{}) []Change {
+	var out []Change
+	diff("$", old, new, &out)
+	return out
+}
+
+func diff(path string, old, new interface{}, out *[]Change) {
+	switch o := old.(type) {
+	case map[string]interface{}:
+		if n, ok := new.(map[string]interface{}); ok {
+			keys := map[string]bool{}
+			for k := range o {
+				keys[k] = true
+			}
+			for k := range n {
+				keys[k] = true
+			}
+			for _, k := range sortedKeys(keys) {
+				ov, inOld := o[k]
+				nv, inNew := n[k]
+				child := Child(path, k)
+				switch {
+				case !inNew:
+					*out = append(*out, Change{Path: child, Kind: Removed, Old: ov})
+				case !inOld:
+					*out = append(*out, Change{Path: child, Kind: Added, New: nv})
+				default:
+					diff(child, ov, nv, out)
+				}
+			}
+			return
+		}
+	case []interface{}:
+		if n, ok := new.([]interface{}); ok {
+			for i := 0; i < len(o) || i < len(n); i++ {
+				child := fmt.Sprintf("%s[%d]", path, i)
+				switch {
+				case i >= len(n):
+					*out = append(*out, Change{Path: child, Kind: Removed, Old: o[i]})
+				case i >= len(o):
+					*out = append(*out, Change{Path: child, Kind: Added, New: n[i]})
+				default:
+					diff(child, o[i], n[i], out)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(old, new) {
+		*out = append(*out, Change{Path: path, Kind: Changed, Old: old, New: new})
+	}
+}
+
+var plainKey = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$-]*$`)
+
+// Child renders the path of an object member: $.name or $["odd key"].
+func Child(path, key string) string {
+	if plainKey.MatchString(key) {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
+
+// Pattern is a compiled JSON path pattern for Mask: $.a.b, $.items[0],
+// $.items[*].id, $["odd key"] and $..createdAt (any depth).
+type Pattern struct {
+	source string
+	steps  []patternStep
+}
+
+type patternStep struct {
+	key       string
+	index     int
+	isIndex   bool
+	wildcard  bool // * or [*]
+	recursive bool // .. before the step
+}
+
+func (p *Pattern) String() string { return p.source }
+
+// Compile parses a pattern; the leading $ is optional.
+func Compile(path string) (*Pattern, error) {
+	p := &Pattern{source: path}
+	s := path
+	if len(s) > 0 && s[0] == '$' {
+		s = s[1:]
+	}
+	for len(s) > 0 {
+		var st patternStep
+		switch {
+		case len(s) >= 2 && s[:2] == "..":
+			st.recursive = true
+			s = s[2:]
+		case s[0] == '.':
+			s = s[1:]
+		case s[0] == '[':
+		default:
+			if len(p.steps) > 0 {
+				return nil, fmt.Errorf("pattern %q: unexpected %q", path, s)
+			}
+		}
+
+		if len(s) > 0 && s[0] == '[' {
+			end := closingBracket(s)
+			if end < 0 {
+				return nil, fmt.Errorf("pattern %q: missing ]", path)
+			}
+			inner := s[1:end]
+			s = s[end+1:]
+			switch {
+			case inner == "*":
+				st.wildcard = true
+			case len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\''):
+				key, err := unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("pattern %q: %w", path, err)
+				}
+				st.key = key
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("pattern %q: bad index [%s]", path, inner)
+				}
+				st.index, st.isIndex = i, true
+			}
+		} else {
+			n := 0
+			for n < len(s) && s[n] != '.' && s[n] != '[' {
+				n++
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("pattern %q: empty name", path)
+			}
+			if s[:n] == "*" {
+				st.wildcard = true
+			} else {
+				st.key = s[:n]
+			}
+			s = s[n:]
+		}
+		p.steps = append(p.steps, st)
+	}
+	return p, nil
+}
+
+// CompileAll compiles a list of patterns.
+func CompileAll(paths []string) ([]*Pattern, error) {
+	out := make([]*Pattern, 0, len(paths))
+	for _, path := range paths {
+		p, err := Compile(path)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		if s[len(s)-1] != '\'' {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	}
+	var key string
+	if err := json.Unmarshal([]byte(s), &key); err != nil {
+		return "", fmt.Errorf("bad key %s", s)
+	}
+	return key, nil
+}
+
+// match reports whether the concrete path (string keys and int indexes)
+// matches the pattern steps.
+func match(steps []patternStep, path []interface{}) bool {
+	if len(steps) == 0 {
+		return len(path) == 0
+	}
+	st := steps[0]
+	if st.recursive {
+		// ..x matches x at this level or any deeper one
+		for skip := 0; skip < len(path); skip++ {
+			if matchStep(st, path[skip]) && match(steps[1:], path[skip+1:]) {
+				return true
+			}
+		}
+		return false
+	}
+	return len(path) > 0 && matchStep(st, path[0]) && match(steps[1:], path[1:])
+}
+
+func matchStep(st patternStep, seg interface{}) bool {
+	if st.wildcard {
+		return true
+	}
+	switch s := seg.(type) {
+	case string:
+		return !st.isIndex && st.key == s
+	case int:
+		return st.isIndex && st.index == s
+	}
+	return false
+}
+
+// Mask returns a copy of v in which every value selected by a pattern is
+// replaced by placeholder. Keys stay, so a missing value is still a change.
+func Mask(v interface{}, patterns []*Pattern, placeholder interface{}) interface{} {
+	if len(patterns) == 0 {
+		return v
+	}
+	return mask(v, nil, patterns, placeholder)
+}
+
+func mask(v interface{}, path []interface{}, patterns []*Pattern, placeholder interface{}) interface{} {
+	for _, p := range patterns {
+		if len(path) > 0 && match(p.steps, path) {
+			return placeholder
+		}
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, child := range t {
+			out[k] = mask(child, append(path[:len(path):len(path)], k), patterns, placeholder)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, child := range t {
+			out[i] = mask(child, append(path[:len(path):len(path)], i), patterns, placeholder)
+		}
+		return out
+	}
+	return v
+}
+
+// show renders a value compactly for change lines.
+func show(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	s := strings.TrimSuffix(buf.String(), "\n")
+	if len(s) > 120 {
+		s = s[:117] + "..."
+	}
+	return s
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,99 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Get returns the value at path in a decoded JSON document
+// (map[string]interface{} / []interface{} / scalars, as produced by
+// encoding/json). Supported syntax: $, .key, ['key'], ["key"] and [index].
+// The leading $ is optional.
+func Get(doc interface{}, path string) (interface{}, error) {
+	steps, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, st := range steps {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			if st.isIndex {
+				return nil, fmt.Errorf("%s: cannot index object with [%d]", path, st.index)
+			}
+			v, ok := node[st.key]
+			if !ok {
+				return nil, fmt.Errorf("%s: key %q not found", path, st.key)
+			}
+			cur = v
+		case []interface{}:
+			if !st.isIndex {
+				return nil, fmt.Errorf("%s: cannot read key %q of array", path, st.key)
+			}
+			i := st.index
+			if i < 0 {
+				i += len(node)
+			}
+			if i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("%s: index %d out of range", path, st.index)
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("%s: cannot descend into %T", path, cur)
+		}
+	}
+	return cur, nil
+}
+
+type step struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func parse(path string) ([]step, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+
+	var steps []step
+	for i := 0; i < len(p); {
+		switch p[i] {
+		case '.':
+			i++
+			j := i
+			for j < len(p) && p[j] != '.' && p[j] != '[' {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("%s: empty key at offset %d", path, i)
+			}
+			steps = append(steps, step{key: p[i:j]})
+			i = j
+		case '[':
+			end := strings.IndexByte(p[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%s: unterminated [", path)
+			}
+			inner := strings.TrimSpace(p[i+1 : i+end])
+			i += end + 1
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, step{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid index [%s]", path, inner)
+			}
+			steps = append(steps, step{index: n, isIndex: true})
+		default:
+			// Allow a bare first key ("data.id" instead of "$.data.id").
+			if len(steps) == 0 && i == 0 {
+				p = "." + p
+				continue
+			}
+			return nil, fmt.Errorf("%s: unexpected %q at offset %d", path, p[i], i)
+		}
+	}
+	return steps, nil
+}
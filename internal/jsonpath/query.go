@@ -0,0 +1,532 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Query evaluates a JSONPath expression against a decoded JSON document and
+// returns every matching value, in document order. Supported syntax:
+//
+//	$              root
+//	.name ['name'] child member
+//	.* [*]         all children
+//	..name ..*     recursive descent
+//	[n] [-1]       array index
+//	[a,b] ['x','y'] union of indexes or names
+//	[start:end]    array slice
+//	[?(@.x > 1)]   filter (==, !=, <, <=, >, >=, =~, or bare @.x for existence)
+func Query(doc interface{}, expr string) ([]interface{}, error) {
+	segs, err := parseQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []interface{}{doc}
+	for _, seg := range segs {
+		var next []interface{}
+		for _, n := range nodes {
+			if seg.recursive {
+				for _, d := range descendants(n) {
+					next = append(next, seg.sel.apply(d)...)
+				}
+				continue
+			}
+			next = append(next, seg.sel.apply(n)...)
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// IsDefinite reports whether expr can match at most one value (no
+// wildcards, unions, slices, filters or recursive descent).
+func IsDefinite(expr string) bool {
+	segs, err := parseQuery(expr)
+	if err != nil {
+		return false
+	}
+	for _, s := range segs {
+		if s.recursive {
+			return false
+		}
+		switch s.sel.(type) {
+		case nameSel, indexSel:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type segment struct {
+	recursive bool
+	sel       selector
+}
+
+type selector interface {
+	apply(node interface{}) []interface{}
+}
+
+type nameSel string
+
+func (s nameSel) apply(node interface{}) []interface{} {
+	if m, ok := node.(map[string]interface{}); ok {
+		if v, ok := m[string(s)]; ok {
+			return []interface{}{v}
+		}
+	}
+	return nil
+}
+
+type indexSel int
+
+func (s indexSel) apply(node interface{}) []interface{} {
+	arr, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(arr)
+	}
+	if i < 0 || i >= len(arr) {
+		return nil
+	}
+	return []interface{}{arr[i]}
+}
+
+type wildcardSel struct{}
+
+func (wildcardSel) apply(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		keys := sortedKeys(n)
+		out := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, n[k])
+		}
+		return out
+	case []interface{}:
+		return append([]interface{}(nil), n...)
+	}
+	return nil
+}
+
+type unionSel []selector
+
+func (u unionSel) apply(node interface{}) []interface{} {
+	var out []interface{}
+	for _, s := range u {
+		out = append(out, s.apply(node)...)
+	}
+	return out
+}
+
+type sliceSel struct {
+	start, end       int
+	hasStart, hasEnd bool
+}
+
+func (s sliceSel) apply(node interface{}) []interface{} {
+	arr, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+	start, end := 0, len(arr)
+	if s.hasStart {
+		start = clampIndex(s.start, len(arr))
+	}
+	if s.hasEnd {
+		end = clampIndex(s.end, len(arr))
+	}
+	if start >= end {
+		return nil
+	}
+	return append([]interface{}(nil), arr[start:end]...)
+}
+
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+type filterSel struct {
+	path  string // relative path after @ ("" for the element itself)
+	op    string // "" means existence test
+	value interface{}
+}
+
+func (f filterSel) apply(node interface{}) []interface{} {
+	var candidates []interface{}
+	switch n := node.(type) {
+	case []interface{}:
+		candidates = n
+	case map[string]interface{}:
+		for _, k := range sortedKeys(n) {
+			candidates = append(candidates, n[k])
+		}
+	default:
+		return nil
+	}
+
+	var out []interface{}
+	for _, c := range candidates {
+		v := c
+		if f.path != "" {
+			got, err := Get(c, f.path)
+			if err != nil {
+				continue
+			}
+			v = got
+		}
+		if f.op == "" || Compare(v, f.op, f.value) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Compare applies a comparison operator to two decoded JSON values.
+// Numbers compare numerically, strings lexically; =~ matches a regular
+// expression given as a string.
+func Compare(a interface{}, op string, b interface{}) bool {
+	switch op {
+	case "==":
+		return equal(a, b)
+	case "!=":
+		return !equal(a, b)
+	case "=~":
+		s, ok1 := a.(string)
+		pattern, ok2 := b.(string)
+		if !ok1 || !ok2 {
+			return false
+		}
+		re, err := regexp.Compile(pattern)
+		return err == nil && re.MatchString(s)
+	}
+
+	if af, ok := a.(float64); ok {
+		if bf, ok := b.(float64); ok {
+			switch op {
+			case "<":
+				return af < bf
+			case "<=":
+				return af <= bf
+			case ">":
+				return af > bf
+			case ">=":
+				return af >= bf
+			}
+		}
+		return false
+	}
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			switch op {
+			case "<":
+				return as < bs
+			case "<=":
+				return as <= bs
+			case ">":
+				return as > bs
+			case ">=":
+				return as >= bs
+			}
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func descendants(node interface{}) []interface{} {
+	out := []interface{}{node}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(n) {
+			out = append(out, descendants(n[k])...)
+		}
+	case []interface{}:
+		for _, v := range n {
+			out = append(out, descendants(v)...)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parseQuery(expr string) ([]segment, error) {
+	p := strings.TrimSpace(expr)
+	if !strings.HasPrefix(p, "$") {
+		return nil, fmt.Errorf("%s: JSONPath must start with $", expr)
+	}
+	p = p[1:]
+
+	var segs []segment
+	for i := 0; i < len(p); {
+		recursive := false
+		switch {
+		case strings.HasPrefix(p[i:], ".."):
+			recursive = true
+			i += 2
+			if i < len(p) && p[i] == '[' {
+				break
+			}
+			name, n := readName(p[i:])
+			if n == 0 {
+				return nil, fmt.Errorf("%s: expected name after ..", expr)
+			}
+			i += n
+			segs = append(segs, segment{recursive: true, sel: nameOrWildcard(name)})
+			continue
+		case p[i] == '.':
+			i++
+			name, n := readName(p[i:])
+			if n == 0 {
+				return nil, fmt.Errorf("%s: expected name after . at offset %d", expr, i)
+			}
+			i += n
+			segs = append(segs, segment{sel: nameOrWildcard(name)})
+			continue
+		}
+
+		if p[i] != '[' {
+			return nil, fmt.Errorf("%s: unexpected %q at offset %d", expr, p[i], i)
+		}
+		end := matchingBracket(p, i)
+		if end < 0 {
+			return nil, fmt.Errorf("%s: unterminated [", expr)
+		}
+		sel, err := parseBracket(p[i+1 : end])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", expr, err)
+		}
+		segs = append(segs, segment{recursive: recursive, sel: sel})
+		i = end + 1
+	}
+	return segs, nil
+}
+
+func nameOrWildcard(name string) selector {
+	if name == "*" {
+		return wildcardSel{}
+	}
+	return nameSel(name)
+}
+
+func readName(s string) (string, int) {
+	if strings.HasPrefix(s, "*") {
+		return "*", 1
+	}
+	j := 0
+	for j < len(s) && s[j] != '.' && s[j] != '[' {
+		j++
+	}
+	return s[:j], j
+}
+
+// matchingBracket returns the index of the ] closing the [ at start,
+// skipping quoted strings and nested brackets.
+func matchingBracket(s string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+			if depth == 0 && c == ']' {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseBracket(inner string) (selector, error) {
+	inner = strings.TrimSpace(inner)
+	switch {
+	case inner == "*":
+		return wildcardSel{}, nil
+	case strings.HasPrefix(inner, "?"):
+		return parseFilter(strings.TrimSpace(inner[1:]))
+	}
+
+	parts := splitTopLevel(inner, ',')
+	if len(parts) > 1 {
+		var u unionSel
+		for _, part := range parts {
+			s, err := parseBracket(part)
+			if err != nil {
+				return nil, err
+			}
+			u = append(u, s)
+		}
+		return u, nil
+	}
+
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return nameSel(inner[1 : len(inner)-1]), nil
+	}
+	if strings.Contains(inner, ":") {
+		var s sliceSel
+		bounds := strings.SplitN(inner, ":", 3)
+		if b := strings.TrimSpace(bounds[0]); b != "" {
+			n, err := strconv.Atoi(b)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice start %q", b)
+			}
+			s.start, s.hasStart = n, true
+		}
+		if b := strings.TrimSpace(bounds[1]); b != "" {
+			n, err := strconv.Atoi(b)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice end %q", b)
+			}
+			s.end, s.hasEnd = n, true
+		}
+		return s, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector [%s]", inner)
+	}
+	return indexSel(n), nil
+}
+
+// parseFilter parses "(@.path op literal)" or "(@.path)".
+func parseFilter(s string) (selector, error) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return nil, fmt.Errorf("filter must be written as ?(...)")
+	}
+	s = strings.TrimSpace(s[1 : len(s)-1])
+	if !strings.HasPrefix(s, "@") {
+		return nil, fmt.Errorf("filter must start with @")
+	}
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "=~", "<", ">"} {
+		idx := indexOutsideQuotes(s, op)
+		if idx < 0 {
+			continue
+		}
+		left := strings.TrimSpace(s[1:idx])
+		right := strings.TrimSpace(s[idx+len(op):])
+		val, err := ParseLiteral(right)
+		if err != nil {
+			return nil, err
+		}
+		return filterSel{path: left, op: op, value: val}, nil
+	}
+	return filterSel{path: strings.TrimSpace(s[1:])}, nil
+}
+
+// ParseLiteral parses a JSON literal, also accepting single-quoted strings.
+func ParseLiteral(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strconv.Unquote(s)
+	}
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid literal %q", s)
+	}
+	return f, nil
+}
+
+func indexOutsideQuotes(s, sub string) int {
+	var quote byte
+	for i := 0; i+len(sub) <= len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if s[i:i+len(sub)] == sub {
+			return i
+		}
+	}
+	return -1
+}
+
+func splitTopLevel(s string, sep byte) []string {
+	var (
+		parts []string
+		depth int
+		quote byte
+		last  int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
@@ -0,0 +1,122 @@
+// Package jsonrpc builds JSON-RPC 2.0 requests and decodes responses.
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Version is the only protocol version supported.
+const Version = "2.0"
+
+// Request is one call. A nil ID makes it a notification (no response).
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      *int64          `json:"id,omitempty"`
+}
+
+// Response is the answer to one call.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error is the error object of a failed call.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Standard error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// CodeName describes a code in words.
+func CodeName(code int) string {
+	switch {
+	case code == CodeParseError:
+		return "Parse error"
+	case code == CodeInvalidRequest:
+		return "Invalid request"
+	case code == CodeMethodNotFound:
+		return "Method not found"
+	case code == CodeInvalidParams:
+		return "Invalid params"
+	case code == CodeInternalError:
+		return "Internal error"
+	case code <= -32000 && code >= -32099:
+		return "Server error"
+	}
+	return "Application error"
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s (%d): %s", CodeName(e.Code), e.Code, e.Message)
+	if len(e.Data) > 0 && string(e.Data) != "null" {
+		msg += "; data: " + string(e.Data)
+	}
+	return msg
+}
+
+// NewRequest builds a call with the given id (nil for a notification).
+// params must be a JSON array, a JSON object, or empty.
+func NewRequest(method string, params json.RawMessage, id *int64) (Request, error) {
+	if method == "" {
+		return Request{}, fmt.Errorf("method name is required")
+	}
+	if p := bytes.TrimSpace(params); len(p) > 0 && p[0] != '[' && p[0] != '{' {
+		return Request{}, fmt.Errorf("params of %s must be a JSON array or object", method)
+	}
+	return Request{JSONRPC: Version, Method: method, Params: params, ID: id}, nil
+}
+
+// DecodeResponses decodes a single response or a batch response array.
+func DecodeResponses(body []byte) ([]Response, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if body[0] == '[' {
+		var batch []Response
+		if err := json.Unmarshal(body, &batch); err != nil {
+			return nil, fmt.Errorf("decode batch response: %w", err)
+		}
+		for i, r := range batch {
+			if err := r.check(); err != nil {
+				return nil, fmt.Errorf("batch response %d: %w", i+1, err)
+			}
+		}
+		return batch, nil
+	}
+	var one Response
+	if err := json.Unmarshal(body, &one); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if err := one.check(); err != nil {
+		return nil, err
+	}
+	return []Response{one}, nil
+}
+
+// check rejects JSON that is not a JSON-RPC 2.0 response object, such as
+// {"message":"unauthorized"} from a proxy.
+func (r Response) check() error {
+	if r.JSONRPC != Version {
+		return fmt.Errorf("not a JSON-RPC 2.0 response (jsonrpc is %q)", r.JSONRPC)
+	}
+	if len(r.Result) == 0 && r.Error == nil {
+		return fmt.Errorf("not a JSON-RPC 2.0 response (no result or error)")
+	}
+	return nil
+}
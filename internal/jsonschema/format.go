@@ -0,0 +1,70 @@
+package jsonschema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	uuidFormat     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameFormat = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+)
+
+// checkFormat validates the common "format" values and returns why s does
+// not match, or "" when it does. Unknown formats are not checked.
+func checkFormat(format, s string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s)); err != nil {
+			return "expected RFC 3339 date-time"
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return "expected YYYY-MM-DD"
+		}
+	case "time":
+		if _, err := time.Parse(time.RFC3339Nano, "2000-01-01T"+strings.ToUpper(s)); err != nil {
+			return "expected HH:MM:SS with a time zone"
+		}
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return "expected an address like name@example.com"
+		}
+	case "uuid":
+		if !uuidFormat.MatchString(s) {
+			return "expected 8-4-4-4-12 hex digits"
+		}
+	case "uri":
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" {
+			return "expected an absolute URI"
+		}
+	case "uri-reference":
+		if _, err := url.Parse(s); err != nil {
+			return err.Error()
+		}
+	case "ipv4":
+		ip := net.ParseIP(s)
+		if ip == nil || ip.To4() == nil || strings.Contains(s, ":") {
+			return "expected a dotted IPv4 address"
+		}
+	case "ipv6":
+		if ip := net.ParseIP(s); ip == nil || !strings.Contains(s, ":") {
+			return "expected an IPv6 address"
+		}
+	case "hostname":
+		if len(s) > 253 || !hostnameFormat.MatchString(s) {
+			return "expected a DNS host name"
+		}
+	case "regex":
+		if _, err := regexp.Compile(s); err != nil {
+			return err.Error()
+		}
+	}
+	return ""
+}
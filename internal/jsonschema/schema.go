@@ -0,0 +1,299 @@
+// Package jsonschema validates decoded JSON values against JSON Schema
+// (draft 2020-12) documents. It supports the validation and applicator
+// vocabularies, local and file $refs, $anchor and the common formats.
+// unevaluatedProperties/Items and $dynamicRef are not supported: schemas
+// using them are rejected instead of passing unchecked.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Schema is a loaded schema document together with any documents its
+// $refs point to.
+type Schema struct {
+	root  *document
+	entry interface{}          // the schema to validate against (root.value unless embedded)
+	docs  map[string]*document // by absolute file path
+	re    map[string]*regexp.Regexp
+
+	// OpenAPI30 applies the OpenAPI 3.0 schema dialect: "nullable: true"
+	// lets a typed schema accept null, and boolean exclusiveMinimum/Maximum
+	// make minimum/maximum exclusive.
+	OpenAPI30 bool
+}
+
+// document is one schema file.
+type document struct {
+	path    string // "" for schemas parsed from memory
+	value   interface{}
+	anchors map[string]interface{}
+}
+
+// Error is one validation failure.
+type Error struct {
+	Path     string // instance location, e.g. $.items[2].id
+	Location string // schema location, e.g. #/properties/items/items/properties/id/type
+	Message  string
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("%s: %s (schema %s)", e.Path, e.Message, e.Location)
+}
+
+// Load reads a schema from a JSON file. Relative file $refs are resolved
+// against the file's directory.
+func Load(path string) (*Schema, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	s := newSchema()
+	doc, err := s.load(abs)
+	if err != nil {
+		return nil, err
+	}
+	s.root, s.entry = doc, doc.value
+	return s, nil
+}
+
+// Parse reads a schema from JSON text; file $refs are resolved against the
+// current directory.
+func Parse(data []byte) (*Schema, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %w", err)
+	}
+	return FromValue(v)
+}
+
+// FromValue wraps an already decoded schema (e.g. one embedded in an
+// OpenAPI document).
+func FromValue(v interface{}) (*Schema, error) {
+	if err := checkSchema(v); err != nil {
+		return nil, err
+	}
+	s := newSchema()
+	s.root = &document{value: v, anchors: collectAnchors(v)}
+	s.entry = v
+	return s, nil
+}
+
+// Embedded wraps a schema that lives inside a larger document (e.g. an
+// OpenAPI document at path): "#/..." refs resolve against root and file
+// refs against the document's directory.
+func Embedded(root interface{}, path string, schema interface{}) (*Schema, error) {
+	if err := checkSchema(schema); err != nil {
+		return nil, err
+	}
+	s := newSchema()
+	s.root = &document{path: path, value: root, anchors: collectAnchors(root)}
+	if path != "" {
+		s.docs[path] = s.root
+	}
+	s.entry = schema
+	return s, nil
+}
+
+func newSchema() *Schema {
+	return &Schema{docs: map[string]*document{}, re: map[string]*regexp.Regexp{}}
+}
+
+func (s *Schema) load(abs string) (*document, error) {
+	if d, ok := s.docs[abs]; ok {
+		return d, nil
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("read schema: %w", err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("%s: schema is not valid JSON: %w", abs, err)
+	}
+	if err := checkSchema(v); err != nil {
+		return nil, fmt.Errorf("%s: %w", abs, err)
+	}
+	d := &document{path: abs, value: v, anchors: collectAnchors(v)}
+	s.docs[abs] = d
+	return d, nil
+}
+
+func checkSchema(v interface{}) error {
+	switch v.(type) {
+	case map[string]interface{}, bool:
+		return checkSupported(v, "#")
+	}
+	return fmt.Errorf("a schema must be a JSON object or boolean")
+}
+
+// unsupported are the keywords the validator cannot enforce.
+var unsupported = []string{"unevaluatedProperties", "unevaluatedItems", "$dynamicRef", "$recursiveRef"}
+
+// unsupportedKeyword returns the first unsupported keyword of one schema
+// object, or "".
+func unsupportedKeyword(sc map[string]interface{}) string {
+	for _, k := range unsupported {
+		if _, ok := sc[k]; ok {
+			return k
+		}
+	}
+	return ""
+}
+
+// checkSupported walks v and its subschemas and reports the first keyword
+// that would otherwise be skipped, so such a schema never passes silently.
+func checkSupported(v interface{}, loc string) error {
+	sc, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if k := unsupportedKeyword(sc); k != "" {
+		return fmt.Errorf("%s/%s: keyword %q is not supported", loc, k, k)
+	}
+	for _, k := range sortedKeys(sc) {
+		child := sc[k]
+		switch k {
+		case "properties", "patternProperties", "dependentSchemas", "$defs", "definitions":
+			m, _ := child.(map[string]interface{})
+			for _, name := range sortedKeys(m) {
+				if err := checkSupported(m[name], loc+"/"+k+"/"+escapePointer(name)); err != nil {
+					return err
+				}
+			}
+		case "items", "prefixItems", "allOf", "anyOf", "oneOf":
+			list, ok := child.([]interface{})
+			if !ok {
+				list = []interface{}{child}
+			}
+			for i, c := range list {
+				l := loc + "/" + k
+				if ok {
+					l += "/" + strconv.Itoa(i)
+				}
+				if err := checkSupported(c, l); err != nil {
+					return err
+				}
+			}
+		case "not", "if", "then", "else", "additionalProperties", "contains", "propertyNames":
+			if err := checkSupported(child, loc+"/"+k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectAnchors indexes every "$anchor" in the document.
+func collectAnchors(v interface{}) map[string]interface{} {
+	anchors := map[string]interface{}{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if a, ok := t["$anchor"].(string); ok {
+				anchors[a] = t
+			}
+			for _, child := range t {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range t {
+				walk(child)
+			}
+		}
+	}
+	walk(v)
+	return anchors
+}
+
+// resolveRef finds the target of a $ref relative to doc. It returns the
+// target schema and the document it lives in.
+func (s *Schema) resolveRef(doc *document, ref string) (interface{}, *document, error) {
+	target, fragment, _ := strings.Cut(ref, "#")
+	if target != "" {
+		if u, err := url.Parse(target); err == nil && u.Scheme != "" {
+			return nil, nil, fmt.Errorf("remote $ref %q is not supported (air-gapped); download it and use a file path", ref)
+		}
+		dir := "."
+		if doc.path != "" {
+			dir = filepath.Dir(doc.path)
+		}
+		path := target
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, target)
+		}
+		d, err := s.load(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("$ref %q: %w", ref, err)
+		}
+		doc = d
+	}
+
+	if fragment == "" {
+		return doc.value, doc, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		a, ok := doc.anchors[fragment]
+		if !ok {
+			return nil, nil, fmt.Errorf("$ref %q: anchor not found", ref)
+		}
+		return a, doc, nil
+	}
+	v, err := Pointer(doc.value, fragment)
+	if err != nil {
+		return nil, nil, fmt.Errorf("$ref %q: %w", ref, err)
+	}
+	return v, doc, nil
+}
+
+// Pointer resolves a JSON Pointer (RFC 6901, e.g. "/$defs/user") in v.
+func Pointer(v interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return v, nil
+	}
+	if unescaped, err := url.PathUnescape(pointer); err == nil {
+		pointer = unescaped
+	}
+	cur := v
+	for _, tok := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch t := cur.(type) {
+		case map[string]interface{}:
+			next, ok := t[tok]
+			if !ok {
+				return nil, fmt.Errorf("pointer %s: %q not found", pointer, tok)
+			}
+			cur = next
+		case []interface{}:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, fmt.Errorf("pointer %s: bad index %q", pointer, tok)
+			}
+			cur = t[i]
+		default:
+			return nil, fmt.Errorf("pointer %s: cannot descend into %q", pointer, tok)
+		}
+	}
+	return cur, nil
+}
+
+// regexp compiles and caches a "pattern" keyword.
+func (s *Schema) regexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := s.re[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	s.re[pattern] = re
+	return re, nil
+}
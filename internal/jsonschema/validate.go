@@ -0,0 +1,476 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth guards against $ref cycles that never consume input.
+const maxRefDepth = 64
+
+// Validate checks v (a decoded JSON value) against the schema. It returns
+// every failure found; the error is for a broken schema (bad $ref or
+// pattern), not for an invalid value.
+func (s *Schema) Validate(v interface{}) ([]Error, error) {
+	vd := &validator{s: s}
+	errs := vd.validate(s.entry, s.root, v, "$", "#")
+	return errs, vd.broken
+}
+
+type validator struct {
+	s      *Schema
+	broken error
+	depth  int
+}
+
+func (vd *validator) fail(err error) {
+	if vd.broken == nil {
+		vd.broken = err
+	}
+}
+
+func (vd *validator) validate(schema interface{}, doc *document, v interface{}, path, loc string) []Error {
+	switch sc := schema.(type) {
+	case bool:
+		if !sc {
+			return []Error{{Path: path, Location: loc, Message: "no value is allowed here (schema false)"}}
+		}
+		return nil
+	case map[string]interface{}:
+		return vd.validateObject(sc, doc, v, path, loc)
+	}
+	vd.fail(fmt.Errorf("%s: a schema must be a JSON object or boolean", loc))
+	return nil
+}
+
+func (vd *validator) validateObject(sc map[string]interface{}, doc *document, v interface{}, path, loc string) []Error {
+	var errs []Error
+	add := func(keyword, format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Location: loc + "/" + keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Schemas reached through a $ref into a larger document were not
+	// checked when loading
+	if k := unsupportedKeyword(sc); k != "" {
+		vd.fail(fmt.Errorf("%s/%s: keyword %q is not supported", loc, k, k))
+	}
+
+	if ref, ok := sc["$ref"].(string); ok {
+		target, tdoc, err := vd.s.resolveRef(doc, ref)
+		if err != nil {
+			vd.fail(err)
+		} else if vd.depth >= maxRefDepth {
+			vd.fail(fmt.Errorf("%s: $ref nesting too deep (cycle?)", loc))
+		} else {
+			vd.depth++
+			refLoc := ref
+			if strings.HasPrefix(ref, "#") && tdoc.path != "" && tdoc != vd.s.root {
+				refLoc = tdoc.path + ref
+			}
+			errs = append(errs, vd.validate(target, tdoc, v, path, refLoc)...)
+			vd.depth--
+		}
+	}
+
+	// Generic keywords
+	if t, ok := sc["type"]; ok && !typeMatches(t, v) && !(v == nil && vd.s.OpenAPI30 && sc["nullable"] == true) {
+		add("type", "expected %s, got %s", typeList(t), typeOf(v))
+	}
+	if enum, ok := sc["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if equal(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("enum", "%s is not one of %s", show(v), show(enum))
+		}
+	}
+	if c, ok := sc["const"]; ok && !equal(c, v) {
+		add("const", "%s is not equal to %s", show(v), show(c))
+	}
+
+	switch t := v.(type) {
+	case float64:
+		vd.numberKeywords(sc, t, add)
+	case string:
+		vd.stringKeywords(sc, t, add)
+	case []interface{}:
+		errs = append(errs, vd.arrayKeywords(sc, doc, t, path, loc, add)...)
+	case map[string]interface{}:
+		errs = append(errs, vd.objectKeywords(sc, doc, t, path, loc, add)...)
+	}
+
+	// Applicators
+	if list, ok := sc["allOf"].([]interface{}); ok {
+		for i, sub := range list {
+			errs = append(errs, vd.validate(sub, doc, v, path, fmt.Sprintf("%s/allOf/%d", loc, i))...)
+		}
+	}
+	if list, ok := sc["anyOf"].([]interface{}); ok {
+		var best []Error
+		matched := false
+		for i, sub := range list {
+			e := vd.validate(sub, doc, v, path, fmt.Sprintf("%s/anyOf/%d", loc, i))
+			if len(e) == 0 {
+				matched = true
+				break
+			}
+			if best == nil || len(e) < len(best) {
+				best = e
+			}
+		}
+		if !matched {
+			add("anyOf", "does not match any of the %d anyOf schemas", len(list))
+			errs = append(errs, best...)
+		}
+	}
+	if list, ok := sc["oneOf"].([]interface{}); ok {
+		var (
+			matches []string
+			best    []Error
+		)
+		for i, sub := range list {
+			e := vd.validate(sub, doc, v, path, fmt.Sprintf("%s/oneOf/%d", loc, i))
+			if len(e) == 0 {
+				matches = append(matches, strconv.Itoa(i))
+			} else if best == nil || len(e) < len(best) {
+				best = e
+			}
+		}
+		switch {
+		case len(matches) == 0:
+			add("oneOf", "does not match any of the %d oneOf schemas", len(list))
+			errs = append(errs, best...)
+		case len(matches) > 1:
+			add("oneOf", "matches more than one oneOf schema (%s)", strings.Join(matches, ", "))
+		}
+	}
+	if sub, ok := sc["not"]; ok {
+		if len(vd.validate(sub, doc, v, path, loc+"/not")) == 0 {
+			add("not", "must not match the \"not\" schema")
+		}
+	}
+	if cond, ok := sc["if"]; ok {
+		if len(vd.validate(cond, doc, v, path, loc+"/if")) == 0 {
+			if then, ok := sc["then"]; ok {
+				errs = append(errs, vd.validate(then, doc, v, path, loc+"/then")...)
+			}
+		} else if els, ok := sc["else"]; ok {
+			errs = append(errs, vd.validate(els, doc, v, path, loc+"/else")...)
+		}
+	}
+	return errs
+}
+
+func (vd *validator) numberKeywords(sc map[string]interface{}, n float64, add func(string, string, ...interface{})) {
+	if m, ok := number(sc["multipleOf"]); ok && m > 0 {
+		q := n / m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			add("multipleOf", "%s is not a multiple of %s", show(n), show(m))
+		}
+	}
+	max, exMax := sc["maximum"], sc["exclusiveMaximum"]
+	min, exMin := sc["minimum"], sc["exclusiveMinimum"]
+	if vd.s.OpenAPI30 {
+		// OpenAPI 3.0 (draft-04 style): exclusiveMaximum: true makes
+		// maximum exclusive
+		max, exMax = draft4Bound(max, exMax)
+		min, exMin = draft4Bound(min, exMin)
+	}
+	if m, ok := number(max); ok && n > m {
+		add("maximum", "%s is greater than the maximum %s", show(n), show(m))
+	}
+	if m, ok := number(exMax); ok && n >= m {
+		add("exclusiveMaximum", "%s must be less than %s", show(n), show(m))
+	}
+	if m, ok := number(min); ok && n < m {
+		add("minimum", "%s is less than the minimum %s", show(n), show(m))
+	}
+	if m, ok := number(exMin); ok && n <= m {
+		add("exclusiveMinimum", "%s must be greater than %s", show(n), show(m))
+	}
+}
+
+// draft4Bound turns a bound with a boolean exclusive flag into the numeric
+// form: (5, true) is exclusive 5, (5, false) inclusive 5.
+func draft4Bound(bound, exclusive interface{}) (inclusive, exclusiveBound interface{}) {
+	flag, ok := exclusive.(bool)
+	switch {
+	case !ok:
+		return bound, exclusive
+	case flag:
+		return nil, bound
+	}
+	return bound, nil
+}
+
+func (vd *validator) stringKeywords(sc map[string]interface{}, s string, add func(string, string, ...interface{})) {
+	length := utf8.RuneCountInString(s)
+	if m, ok := number(sc["maxLength"]); ok && float64(length) > m {
+		add("maxLength", "length %d is greater than maxLength %s", length, show(m))
+	}
+	if m, ok := number(sc["minLength"]); ok && float64(length) < m {
+		add("minLength", "length %d is less than minLength %s", length, show(m))
+	}
+	if p, ok := sc["pattern"].(string); ok {
+		re, err := vd.s.regexp(p)
+		if err != nil {
+			vd.fail(err)
+		} else if !re.MatchString(s) {
+			add("pattern", "%s does not match the pattern %s", show(s), p)
+		}
+	}
+	if f, ok := sc["format"].(string); ok {
+		if msg := checkFormat(f, s); msg != "" {
+			add("format", "%s is not a valid %s: %s", show(s), f, msg)
+		}
+	}
+}
+
+func (vd *validator) arrayKeywords(sc map[string]interface{}, doc *document, arr []interface{}, path, loc string, add func(string, string, ...interface{})) []Error {
+	var errs []Error
+	if m, ok := number(sc["maxItems"]); ok && float64(len(arr)) > m {
+		add("maxItems", "%d items, at most %s allowed", len(arr), show(m))
+	}
+	if m, ok := number(sc["minItems"]); ok && float64(len(arr)) < m {
+		add("minItems", "%d items, at least %s required", len(arr), show(m))
+	}
+	if u, _ := sc["uniqueItems"].(bool); u {
+		for i := 0; i < len(arr); i++ {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					add("uniqueItems", "items %d and %d are equal", i, j)
+					i = len(arr) // report once
+					break
+				}
+			}
+		}
+	}
+
+	prefix, _ := sc["prefixItems"].([]interface{})
+	for i, sub := range prefix {
+		if i >= len(arr) {
+			break
+		}
+		errs = append(errs, vd.validate(sub, doc, arr[i], fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("%s/prefixItems/%d", loc, i))...)
+	}
+	if items, ok := sc["items"]; ok {
+		for i := len(prefix); i < len(arr); i++ {
+			errs = append(errs, vd.validate(items, doc, arr[i], fmt.Sprintf("%s[%d]", path, i), loc+"/items")...)
+		}
+	}
+
+	if contains, ok := sc["contains"]; ok {
+		count := 0
+		for _, item := range arr {
+			if len(vd.validate(contains, doc, item, path, loc+"/contains")) == 0 {
+				count++
+			}
+		}
+		minC, maxC := 1.0, math.Inf(1)
+		if m, ok := number(sc["minContains"]); ok {
+			minC = m
+		}
+		if m, ok := number(sc["maxContains"]); ok {
+			maxC = m
+		}
+		if float64(count) < minC {
+			add("contains", "%d item(s) match \"contains\", at least %s required", count, show(minC))
+		}
+		if float64(count) > maxC {
+			add("maxContains", "%d item(s) match \"contains\", at most %s allowed", count, show(maxC))
+		}
+	}
+	return errs
+}
+
+func (vd *validator) objectKeywords(sc map[string]interface{}, doc *document, obj map[string]interface{}, path, loc string, add func(string, string, ...interface{})) []Error {
+	var errs []Error
+	if m, ok := number(sc["maxProperties"]); ok && float64(len(obj)) > m {
+		add("maxProperties", "%d properties, at most %s allowed", len(obj), show(m))
+	}
+	if m, ok := number(sc["minProperties"]); ok && float64(len(obj)) < m {
+		add("minProperties", "%d properties, at least %s required", len(obj), show(m))
+	}
+	if req, ok := sc["required"].([]interface{}); ok {
+		for _, r := range req {
+			if name, ok := r.(string); ok {
+				if _, present := obj[name]; !present {
+					add("required", "missing required property %q", name)
+				}
+			}
+		}
+	}
+	if deps, ok := sc["dependentRequired"].(map[string]interface{}); ok {
+		for name, list := range deps {
+			if _, present := obj[name]; !present {
+				continue
+			}
+			names, _ := list.([]interface{})
+			for _, r := range names {
+				if dep, ok := r.(string); ok {
+					if _, present := obj[dep]; !present {
+						add("dependentRequired", "property %q is required when %q is present", dep, name)
+					}
+				}
+			}
+		}
+	}
+	if deps, ok := sc["dependentSchemas"].(map[string]interface{}); ok {
+		for name, sub := range deps {
+			if _, present := obj[name]; present {
+				errs = append(errs, vd.validate(sub, doc, obj, path, loc+"/dependentSchemas/"+escapePointer(name))...)
+			}
+		}
+	}
+	if names, ok := sc["propertyNames"]; ok {
+		for _, k := range sortedKeys(obj) {
+			errs = append(errs, vd.validate(names, doc, k, childPath(path, k), loc+"/propertyNames")...)
+		}
+	}
+
+	props, _ := sc["properties"].(map[string]interface{})
+	patterns, _ := sc["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := sc["additionalProperties"]
+	for _, k := range sortedKeys(obj) {
+		val := obj[k]
+		kp := childPath(path, k)
+		matched := false
+		if sub, ok := props[k]; ok {
+			matched = true
+			errs = append(errs, vd.validate(sub, doc, val, kp, loc+"/properties/"+escapePointer(k))...)
+		}
+		for _, p := range sortedKeys(patterns) {
+			re, err := vd.s.regexp(p)
+			if err != nil {
+				vd.fail(err)
+				continue
+			}
+			if re.MatchString(k) {
+				matched = true
+				errs = append(errs, vd.validate(patterns[p], doc, val, kp, loc+"/patternProperties/"+escapePointer(p))...)
+			}
+		}
+		if !matched && hasAdditional {
+			if b, ok := additional.(bool); ok && !b {
+				errs = append(errs, Error{Path: kp, Location: loc + "/additionalProperties", Message: fmt.Sprintf("additional property %q is not allowed", k)})
+			} else {
+				errs = append(errs, vd.validate(additional, doc, val, kp, loc+"/additionalProperties")...)
+			}
+		}
+	}
+	return errs
+}
+
+// typeMatches checks the "type" keyword (a name or a list of names).
+func typeMatches(t interface{}, v interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, v)
+	case []interface{}:
+		for _, x := range tt {
+			if s, ok := x.(string); ok && isType(s, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isType(name string, v interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n) && !math.IsInf(n, 0)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return typeOf(v) == name
+}
+
+func typeOf(v interface{}) string {
+	switch n := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func typeList(t interface{}) string {
+	if list, ok := t.([]interface{}); ok {
+		parts := make([]string, 0, len(list))
+		for _, x := range list {
+			parts = append(parts, fmt.Sprint(x))
+		}
+		return strings.Join(parts, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func number(v interface{}) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// show renders a value as compact JSON for messages.
+func show(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(data) > 80 {
+		return string(data[:77]) + "..."
+	}
+	return string(data)
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// childPath appends an object key to a $.a.b style path.
+func childPath(path, key string) string {
+	if identifier.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
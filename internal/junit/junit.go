@@ -0,0 +1,98 @@
+// Package junit writes test results as JUnit XML, the format CI servers
+// (Jenkins, GitLab, GitHub Actions reporters) read.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Suites is the <testsuites> root.
+type Suites struct {
+	XMLName  xml.Name `xml:"testsuites"`
+	Name     string   `xml:"name,attr,omitempty"`
+	Tests    int      `xml:"tests,attr"`
+	Failures int      `xml:"failures,attr"`
+	Errors   int      `xml:"errors,attr"`
+	Skipped  int      `xml:"skipped,attr"`
+	Time     Seconds  `xml:"time,attr"`
+	Suites   []Suite  `xml:"testsuite"`
+}
+
+// Suite is one <testsuite> (one suite file).
+type Suite struct {
+	Name      string  `xml:"name,attr"`
+	Tests     int     `xml:"tests,attr"`
+	Failures  int     `xml:"failures,attr"`
+	Errors    int     `xml:"errors,attr"`
+	Skipped   int     `xml:"skipped,attr"`
+	Time      Seconds `xml:"time,attr"`
+	Timestamp string  `xml:"timestamp,attr,omitempty"`
+	Cases     []Case  `xml:"testcase"`
+}
+
+// Case is one <testcase>; at most one of Failure, Error and Skipped is set.
+type Case struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      Seconds  `xml:"time,attr"`
+	Failure   *Result  `xml:"failure,omitempty"`
+	Error     *Result  `xml:"error,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
+}
+
+// Result describes a failure or an error.
+type Result struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// Skipped marks a case that did not run.
+type Skipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// Seconds renders a duration as seconds with millisecond precision.
+type Seconds time.Duration
+
+func (s Seconds) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{Name: name, Value: fmt.Sprintf("%.3f", time.Duration(s).Seconds())}, nil
+}
+
+// Add appends a suite and updates the totals.
+func (s *Suites) Add(suite Suite) {
+	for _, c := range suite.Cases {
+		suite.Tests++
+		switch {
+		case c.Failure != nil:
+			suite.Failures++
+		case c.Error != nil:
+			suite.Errors++
+		case c.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+	s.Suites = append(s.Suites, suite)
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+	s.Errors += suite.Errors
+	s.Skipped += suite.Skipped
+	s.Time += suite.Time
+}
+
+// WriteFile writes the report to path.
+func (s *Suites) WriteFile(path string) error {
+	data, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write JUnit report: %w", err)
+	}
+	return nil
+}
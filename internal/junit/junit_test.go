@@ -0,0 +1,101 @@
+package junit
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name  string
+		cases []Case
+		want  [4]int // tests, failures, errors, skipped
+	}{
+		{"empty", nil, [4]int{0, 0, 0, 0}},
+		{"passed", []Case{{Name: "a"}, {Name: "b"}}, [4]int{2, 0, 0, 0}},
+		{"mixed", []Case{
+			{Name: "ok"},
+			{Name: "fail", Failure: &Result{Message: "1 assertion(s) failed"}},
+			{Name: "err", Error: &Result{Message: "connection refused"}},
+			{Name: "skip", Skipped: &Skipped{Message: "skipped"}},
+			{Name: "skip 2", Skipped: &Skipped{}},
+		}, [4]int{5, 1, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Suites
+			s.Add(Suite{Name: "one", Cases: tt.cases, Time: Seconds(time.Second)})
+			got := s.Suites[0]
+			if [4]int{got.Tests, got.Failures, got.Errors, got.Skipped} != tt.want {
+				t.Errorf("suite counts = %d/%d/%d/%d, want %v", got.Tests, got.Failures, got.Errors, got.Skipped, tt.want)
+			}
+			if [4]int{s.Tests, s.Failures, s.Errors, s.Skipped} != tt.want {
+				t.Errorf("totals = %d/%d/%d/%d, want %v", s.Tests, s.Failures, s.Errors, s.Skipped, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddAccumulates(t *testing.T) {
+	var s Suites
+	s.Add(Suite{Name: "a", Cases: []Case{{Name: "x", Failure: &Result{}}}, Time: Seconds(250 * time.Millisecond)})
+	s.Add(Suite{Name: "b", Cases: []Case{{Name: "y"}, {Name: "z", Error: &Result{}}}, Time: Seconds(time.Second)})
+	if s.Tests != 3 || s.Failures != 1 || s.Errors != 1 || len(s.Suites) != 2 {
+		t.Errorf("totals = %d tests, %d failures, %d errors, %d suites; want 3, 1, 1, 2", s.Tests, s.Failures, s.Errors, len(s.Suites))
+	}
+	if time.Duration(s.Time) != 1250*time.Millisecond {
+		t.Errorf("time = %v, want 1.25s", time.Duration(s.Time))
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	s := &Suites{Name: "run"}
+	s.Add(Suite{Name: "users", Time: Seconds(1500 * time.Millisecond), Cases: []Case{
+		{Name: "list", Classname: "users", Time: Seconds(12 * time.Millisecond)},
+		{Name: "create <admin>", Classname: "users", Failure: &Result{
+			Message: "1 assertion(s) failed",
+			Type:    "assertion",
+			Text:    `$.name == "Ada": got "Bob"`,
+		}, SystemOut: "> POST /users"},
+		{Name: "delete", Classname: "users", Skipped: &Skipped{Message: "--bail after a failure"}},
+	}})
+	path := filepath.Join(t.TempDir(), "report.xml")
+	if err := s.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		xml.Header,
+		`<testsuites name="run" tests="3" failures="1" errors="0" skipped="1" time="1.500">`,
+		`<testsuite name="users" tests="3" failures="1" errors="0" skipped="1" time="1.500">`,
+		`<testcase name="list" classname="users" time="0.012"></testcase>`,
+		`<testcase name="create &lt;admin&gt;" classname="users" time="0.000">`,
+		`<failure message="1 assertion(s) failed" type="assertion">$.name == &#34;Ada&#34;: got &#34;Bob&#34;</failure>`,
+		`<system-out>&gt; POST /users</system-out>`,
+		`<skipped message="--bail after a failure"></skipped>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q:\n%s", want, out)
+		}
+	}
+
+	dec := xml.NewDecoder(strings.NewReader(out))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("report is not well-formed XML: %v", err)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+// Package openapi reads OpenAPI 3.x documents (JSON or YAML): servers,
+// operations with their parameters, request bodies and responses, and
+// security schemes.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/jsonschema"
+	"go-rest-api-cli-demo/internal/yaml"
+)
+
+// Methods are the operation keys of a path item, in display order.
+var Methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is a loaded OpenAPI document.
+type Document struct {
+	Path       string // absolute file path
+	Version    string // the "openapi" field, e.g. 3.0.3
+	Title      string
+	APIVersion string
+
+	Servers         []Server
+	Operations      []*Operation // sorted by path, then method
+	SecuritySchemes map[string]SecurityScheme
+	Security        []Requirement // global requirements
+
+	root map[string]interface{}
+}
+
+// Server is an entry of "servers".
+type Server struct {
+	URL         string
+	Description string
+	Variables   map[string]ServerVariable
+}
+
+// ServerVariable is a {name} placeholder of a server URL.
+type ServerVariable struct {
+	Default string
+	Enum    []string
+}
+
+// Operation is one method of one path.
+type Operation struct {
+	ID         string // operationId, or "METHOD /path" when the spec has none
+	Method     string // upper case
+	Path       string // template, e.g. /pets/{petId}
+	Summary    string
+	Tags       []string
+	Deprecated bool
+
+	Parameters  []Parameter // path-level and operation-level, refs resolved
+	RequestBody *RequestBody
+	Responses   map[string]Response // by status code, "2XX" or "default"
+	// Security is nil when the operation inherits the global requirements.
+	Security []Requirement
+}
+
+// Parameter is a path, query, header or cookie parameter.
+type Parameter struct {
+	Name        string
+	In          string // path|query|header|cookie
+	Description string
+	Required    bool
+	Schema      interface{}
+}
+
+// RequestBody describes the accepted bodies by media type.
+type RequestBody struct {
+	Required bool
+	Content  map[string]interface{} // media type -> schema (nil if none)
+}
+
+// Response is a declared response.
+type Response struct {
+	Description string
+	Content     map[string]interface{} // media type -> schema (nil if none)
+}
+
+// SecurityScheme is an entry of components.securitySchemes.
+type SecurityScheme struct {
+	Name   string // key in securitySchemes
+	Type   string // http|apiKey|oauth2|openIdConnect|mutualTLS
+	Scheme string // http: basic|bearer|...
+	In     string // apiKey: header|query|cookie
+	Param  string // apiKey: header/query/cookie name
+}
+
+// Requirement maps scheme names to scopes; all schemes of one requirement
+// apply together.
+type Requirement map[string][]string
+
+// Load reads an OpenAPI 3.x document from a .json, .yaml or .yml file.
+func Load(path string) (*Document, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("read OpenAPI document: %w", err)
+	}
+
+	var v interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(data, &v)
+	} else {
+		v, err = yaml.Unmarshal(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	root, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: not an OpenAPI document", path)
+	}
+	doc, err := parse(root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	doc.Path = abs
+	return doc, nil
+}
+
+func parse(root map[string]interface{}) (*Document, error) {
+	version := str(root["openapi"])
+	if version == "" {
+		if str(root["swagger"]) != "" {
+			return nil, fmt.Errorf("swagger 2.0 documents are not supported; convert the document to OpenAPI 3")
+		}
+		return nil, fmt.Errorf("missing \"openapi\" version field")
+	}
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %s (want 3.x)", version)
+	}
+
+	info := obj(root["info"])
+	doc := &Document{
+		Version:         version,
+		Title:           str(info["title"]),
+		APIVersion:      str(info["version"]),
+		Servers:         parseServers(root["servers"]),
+		SecuritySchemes: map[string]SecurityScheme{},
+		Security:        parseSecurity(root["security"]),
+		root:            root,
+	}
+
+	for name, raw := range obj(obj(root["components"])["securitySchemes"]) {
+		s := obj(doc.resolve(raw))
+		doc.SecuritySchemes[name] = SecurityScheme{
+			Name:   name,
+			Type:   str(s["type"]),
+			Scheme: strings.ToLower(str(s["scheme"])),
+			In:     str(s["in"]),
+			Param:  str(s["name"]),
+		}
+	}
+
+	for path, rawItem := range obj(root["paths"]) {
+		item := obj(doc.resolve(rawItem))
+		shared := doc.parseParameters(item["parameters"])
+		for _, m := range Methods {
+			rawOp, ok := item[m]
+			if !ok {
+				continue
+			}
+			doc.Operations = append(doc.Operations, doc.parseOperation(path, m, obj(rawOp), shared))
+		}
+	}
+	sort.Slice(doc.Operations, func(i, j int) bool {
+		a, b := doc.Operations[i], doc.Operations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return methodRank(a.Method) < methodRank(b.Method)
+	})
+	return doc, nil
+}
+
+func (d *Document) parseOperation(path, method string, raw map[string]interface{}, shared []Parameter) *Operation {
+	op := &Operation{
+		ID:         str(raw["operationId"]),
+		Method:     strings.ToUpper(method),
+		Path:       path,
+		Summary:    str(raw["summary"]),
+		Deprecated: raw["deprecated"] == true,
+		Responses:  map[string]Response{},
+	}
+	if op.ID == "" {
+		op.ID = op.Method + " " + path
+	}
+	for _, t := range list(raw["tags"]) {
+		op.Tags = append(op.Tags, str(t))
+	}
+
+	// Operation parameters override path-level ones with the same name+in
+	params := append([]Parameter(nil), shared...)
+	for _, p := range d.parseParameters(raw["parameters"]) {
+		replaced := false
+		for i := range params {
+			if params[i].Name == p.Name && params[i].In == p.In {
+				params[i], replaced = p, true
+			}
+		}
+		if !replaced {
+			params = append(params, p)
+		}
+	}
+	op.Parameters = params
+
+	if rb, ok := raw["requestBody"]; ok {
+		body := obj(d.resolve(rb))
+		op.RequestBody = &RequestBody{Required: body["required"] == true, Content: d.parseContent(body["content"])}
+	}
+	for code, r := range obj(raw["responses"]) {
+		resp := obj(d.resolve(r))
+		if code != "default" {
+			code = strings.ToUpper(code)
+		}
+		op.Responses[code] = Response{Description: str(resp["description"]), Content: d.parseContent(resp["content"])}
+	}
+	if sec, ok := raw["security"]; ok {
+		op.Security = parseSecurity(sec)
+		if op.Security == nil {
+			op.Security = []Requirement{} // "security: []" disables auth
+		}
+	}
+	return op
+}
+
+func (d *Document) parseParameters(raw interface{}) []Parameter {
+	var out []Parameter
+	for _, r := range list(raw) {
+		p := obj(d.resolve(r))
+		param := Parameter{
+			Name:        str(p["name"]),
+			In:          str(p["in"]),
+			Description: str(p["description"]),
+			Required:    p["required"] == true,
+			Schema:      p["schema"],
+		}
+		if param.Schema == nil {
+			// "content" parameters: take the first media type's schema
+			for _, s := range d.parseContent(p["content"]) {
+				param.Schema = s
+				break
+			}
+		}
+		if param.In == "path" {
+			param.Required = true
+		}
+		out = append(out, param)
+	}
+	return out
+}
+
+func (d *Document) parseContent(raw interface{}) map[string]interface{} {
+	content := map[string]interface{}{}
+	for mediaType, m := range obj(raw) {
+		content[strings.ToLower(mediaType)] = obj(m)["schema"]
+	}
+	return content
+}
+
+func parseServers(raw interface{}) []Server {
+	var out []Server
+	for _, r := range list(raw) {
+		s := obj(r)
+		srv := Server{URL: str(s["url"]), Description: str(s["description"]), Variables: map[string]ServerVariable{}}
+		for name, v := range obj(s["variables"]) {
+			vo := obj(v)
+			sv := ServerVariable{Default: str(vo["default"])}
+			for _, e := range list(vo["enum"]) {
+				sv.Enum = append(sv.Enum, str(e))
+			}
+			srv.Variables[name] = sv
+		}
+		out = append(out, srv)
+	}
+	return out
+}
+
+func parseSecurity(raw interface{}) []Requirement {
+	var out []Requirement
+	for _, r := range list(raw) {
+		req := Requirement{}
+		for name, scopes := range obj(r) {
+			var s []string
+			for _, x := range list(scopes) {
+				s = append(s, str(x))
+			}
+			req[name] = s
+		}
+		out = append(out, req)
+	}
+	return out
+}
+
+// resolve follows a local "#/..." $ref (one level at a time, up to a
+// limit); other values are returned unchanged.
+func (d *Document) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj(v)["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return v
+		}
+		target, err := jsonschema.Pointer(d.root, ref[1:])
+		if err != nil {
+			return v
+		}
+		v = target
+	}
+	return v
+}
+
+// Find returns the operation with the given operationId, or "METHOD /path".
+func (d *Document) Find(id string) (*Operation, error) {
+	for _, op := range d.Operations {
+		if op.ID == id {
+			return op, nil
+		}
+	}
+	if method, path, ok := strings.Cut(id, " "); ok {
+		for _, op := range d.Operations {
+			if strings.EqualFold(op.Method, method) && op.Path == strings.TrimSpace(path) {
+				return op, nil
+			}
+		}
+	}
+	for _, op := range d.Operations {
+		if strings.EqualFold(op.ID, id) {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("operation %q not found (see \"openapi list\")", id)
+}
+
+// templateVar matches {name} placeholders in paths and server URLs.
+var templateVar = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Resolve returns the server URL with {variables} replaced by the given
+// values or their defaults.
+func (s Server) Resolve(values map[string]string) string {
+	return templateVar.ReplaceAllStringFunc(s.URL, func(m string) string {
+		name := m[1 : len(m)-1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		if v, ok := s.Variables[name]; ok {
+			return v.Default
+		}
+		return m
+	})
+}
+
+// Expand fills the {name} placeholders of a path template.
+func Expand(template string, values map[string]string) (string, error) {
+	var missing []string
+	out := templateVar.ReplaceAllStringFunc(template, func(m string) string {
+		name := m[1 : len(m)-1]
+		v, ok := values[name]
+		if !ok {
+			missing = append(missing, name)
+			return m
+		}
+		return url.PathEscape(v)
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing path parameter(s): %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// EffectiveSecurity returns the requirements that apply to op.
+func (d *Document) EffectiveSecurity(op *Operation) []Requirement {
+	if op.Security != nil {
+		return op.Security
+	}
+	return d.Security
+}
+
+func methodRank(m string) int {
+	for i, x := range Methods {
+		if strings.EqualFold(x, m) {
+			return i
+		}
+	}
+	return len(Methods)
+}
+
+// SchemaType describes a schema briefly for listings (e.g. "integer",
+// "array of string", "Pet").
+func SchemaType(schema interface{}) string {
+	s := obj(schema)
+	if ref, ok := s["$ref"].(string); ok {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	t := str(s["type"])
+	if t == "array" {
+		return "array of " + SchemaType(s["items"])
+	}
+	if t == "" {
+		return "any"
+	}
+	if f := str(s["format"]); f != "" {
+		return t + " (" + f + ")"
+	}
+	return t
+}
+
+func obj(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+// str renders a scalar; YAML may decode "1.0" as a number.
+func str(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,336 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/jsonschema"
+)
+
+// Violation is one difference between a request or response and the
+// operation that describes it.
+type Violation struct {
+	Where   string // e.g. "query limit", "request body $.name", "status"
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Where + ": " + v.Message
+}
+
+// Match finds the operation for a request. The path is tried as is and
+// with each server's base path (and the given extra prefixes) removed;
+// among matching templates the one with the fewest {params} wins, so
+// /pets/mine beats /pets/{id}. path is the escaped request path, so an
+// encoded "/" stays inside its segment; the returned path parameter
+// values are unescaped.
+func (d *Document) Match(method, path string, prefixes ...string) (*Operation, map[string]string) {
+	candidates := []string{path}
+	for _, s := range d.Servers {
+		prefixes = append(prefixes, serverPath(s.Resolve(nil)))
+	}
+	for _, p := range prefixes {
+		p = strings.TrimRight((&url.URL{Path: p}).EscapedPath(), "/")
+		if p != "" && strings.HasPrefix(path, p+"/") {
+			candidates = append(candidates, strings.TrimPrefix(path, p))
+		}
+	}
+
+	var (
+		best       *Operation
+		bestValues map[string]string
+		bestVars   int
+	)
+	for _, op := range d.Operations {
+		if !strings.EqualFold(op.Method, method) {
+			continue
+		}
+		re, names := pathPattern(op.Path)
+		for _, c := range candidates {
+			m := re.FindStringSubmatch(c)
+			if m == nil {
+				continue
+			}
+			if best == nil || len(names) < bestVars {
+				values := map[string]string{}
+				for i, name := range names {
+					if v, err := url.PathUnescape(m[i+1]); err == nil {
+						values[name] = v
+					} else {
+						values[name] = m[i+1]
+					}
+				}
+				best, bestValues, bestVars = op, values, len(names)
+			}
+			break
+		}
+	}
+	return best, bestValues
+}
+
+// pathPattern turns a path template into a regular expression with one
+// group per {param}.
+func pathPattern(template string) (*regexp.Regexp, []string) {
+	var (
+		b     strings.Builder
+		names []string
+		last  int
+	)
+	b.WriteString("^")
+	for _, loc := range templateVar.FindAllStringSubmatchIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		b.WriteString("([^/]+)")
+		names = append(names, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("/?$")
+	return regexp.MustCompile(b.String()), names
+}
+
+// serverPath returns the path part of a server URL ("/v1" for
+// https://api.example.com/v1).
+func serverPath(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
+
+// Schema wraps a schema of the document for validation; $refs into
+// components resolve against the whole document.
+func (d *Document) Schema(schema interface{}) (*jsonschema.Schema, error) {
+	s, err := jsonschema.Embedded(d.root, d.Path, schema)
+	if err != nil {
+		return nil, err
+	}
+	s.OpenAPI30 = strings.HasPrefix(d.Version, "3.0")
+	return s, nil
+}
+
+// CheckRequest compares a request with op: path, query, header and cookie
+// parameters, the content type and the JSON body. body may be nil for
+// streamed bodies (only the content type is checked then). The error is
+// for a broken schema in the document.
+func (d *Document) CheckRequest(op *Operation, pathValues map[string]string, req *http.Request, body []byte) ([]Violation, error) {
+	var out []Violation
+	query := req.URL.Query()
+
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			if v, ok := pathValues[p.Name]; ok {
+				values = []string{v}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			switch strings.ToLower(p.Name) {
+			case "accept", "content-type", "authorization":
+				continue // described by other means, ignored per the spec
+			}
+			values = req.Header.Values(p.Name)
+		case "cookie":
+			if c, err := req.Cookie(p.Name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+
+		where := p.In + " " + p.Name
+		if len(values) == 0 {
+			if p.Required {
+				out = append(out, Violation{where, "required parameter is missing"})
+			}
+			continue
+		}
+		errs, err := d.checkParam(p, values)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range errs {
+			out = append(out, Violation{where, e})
+		}
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	hasBody := len(body) > 0 || (body == nil && req.Body != nil && req.Body != http.NoBody)
+	switch {
+	case op.RequestBody == nil:
+		if hasBody {
+			out = append(out, Violation{"request body", "the operation does not take a request body"})
+		}
+		return out, nil
+	case !hasBody:
+		if op.RequestBody.Required {
+			out = append(out, Violation{"request body", "a request body is required"})
+		}
+		return out, nil
+	}
+
+	vs, err := d.checkContent("request body", op.RequestBody.Content, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	return append(out, vs...), nil
+}
+
+// CheckResponse compares a response with op: the status must be declared
+// (exactly, as 2XX or by "default"), and the content type and JSON body
+// must match the declared content.
+func (d *Document) CheckResponse(op *Operation, status int, header http.Header, body []byte) ([]Violation, error) {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses[fmt.Sprintf("%dXX", status/100)]
+	}
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		declared := sortedKeys(op.Responses)
+		return []Violation{{"status", fmt.Sprintf("%d is not a declared response (declared: %s)", status, strings.Join(declared, ", "))}}, nil
+	}
+
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if len(resp.Content) == 0 {
+		return []Violation{{"response body", fmt.Sprintf("response %d declares no content, got %d bytes", status, len(body))}}, nil
+	}
+	return d.checkContent("response body", resp.Content, header.Get("Content-Type"), body)
+}
+
+// checkContent checks a body against the declared media types: the
+// content type must be one of them and JSON bodies must match its schema.
+func (d *Document) checkContent(what string, content map[string]interface{}, contentType string, body []byte) ([]Violation, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	key, ok := matchMediaType(content, mediaType)
+	if !ok {
+		return []Violation{{what, fmt.Sprintf("content type %q is not one of %s", mediaType, strings.Join(sortedKeys(content), ", "))}}, nil
+	}
+
+	schema := content[key]
+	if schema == nil || body == nil || !isJSONMediaType(mediaType) {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []Violation{{what, "not valid JSON: " + err.Error()}}, nil
+	}
+	s, err := d.Schema(schema)
+	if err != nil {
+		return nil, fmt.Errorf("%s schema: %w", what, err)
+	}
+	errs, err := s.Validate(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s schema: %w", what, err)
+	}
+	var out []Violation
+	for _, e := range errs {
+		out = append(out, Violation{what + " " + e.Path, fmt.Sprintf("%s (schema %s)", e.Message, e.Location)})
+	}
+	return out, nil
+}
+
+// checkParam validates the raw string values of a parameter against its
+// schema, converting them to the schema's type first.
+func (d *Document) checkParam(p Parameter, values []string) ([]string, error) {
+	if p.Schema == nil {
+		return nil, nil
+	}
+	schema := obj(d.resolve(p.Schema))
+
+	var v interface{}
+	if str(schema["type"]) == "array" {
+		items := obj(d.resolve(schema["items"]))
+		var list []interface{}
+		for _, raw := range values {
+			for _, part := range strings.Split(raw, ",") {
+				list = append(list, convertParam(items, part))
+			}
+		}
+		v = list
+	} else {
+		v = convertParam(schema, values[0])
+	}
+
+	s, err := d.Schema(p.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s schema: %w", p.Name, err)
+	}
+	errs, err := s.Validate(v)
+	if err != nil {
+		return nil, fmt.Errorf("parameter %s schema: %w", p.Name, err)
+	}
+	var out []string
+	for _, e := range errs {
+		msg := e.Message
+		if e.Path != "$" {
+			msg = e.Path + ": " + msg
+		}
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+// convertParam turns a parameter string into the JSON value its schema
+// expects; values that do not convert stay strings (and fail "type").
+func convertParam(schema map[string]interface{}, raw string) interface{} {
+	switch str(schema["type"]) {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// matchMediaType finds the declared media type for mediaType: exact,
+// then "type/*", then "*/*".
+func matchMediaType(content map[string]interface{}, mediaType string) (string, bool) {
+	if _, ok := content[mediaType]; ok {
+		return mediaType, true
+	}
+	for key := range content {
+		if mt, _, err := mime.ParseMediaType(key); err == nil && mt == mediaType {
+			return key, true
+		}
+	}
+	if major, _, ok := strings.Cut(mediaType, "/"); ok {
+		if _, ok := content[major+"/*"]; ok {
+			return major + "/*", true
+		}
+	}
+	if _, ok := content["*/*"]; ok {
+		return "*/*", true
+	}
+	return "", false
+}
+
+func isJSONMediaType(mt string) bool {
+	return mt == "application/json" || strings.HasSuffix(mt, "+json")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,167 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Result is everything a renderer may print about one call.
+type Result struct {
+	Method         string
+	URL            string
+	RequestHeaders http.Header
+	RequestBody    []byte
+
+	Status     string
+	StatusCode int
+	Headers    http.Header
+	Duration   time.Duration
+
+	// Body is the response body after --filter / --pretty, as it is
+	// printed in text mode and written to --out.
+	Body []byte
+}
+
+// Options carries the flags that affect rendering.
+type Options struct {
+	Pretty   bool
+	Raw      bool
+	JSONOnly bool
+}
+
+// Renderer is the output strategy interface.
+type Renderer interface {
+	Render(w io.Writer, r *Result) error
+}
+
+// Formats lists the accepted --output values.
+const Formats = "text|json|yaml|table|csv"
+
+// New returns the renderer for an --output format.
+func New(format string, opts Options) (Renderer, error) {
+	switch strings.ToLower(format) {
+	case "", "text":
+		return Text{Options: opts}, nil
+	case "json":
+		return JSON{Pretty: opts.Pretty}, nil
+	case "yaml":
+		return YAML{}, nil
+	case "table":
+		return Table{}, nil
+	case "csv":
+		return CSV{}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (want %s)", format, Formats)
+}
+
+// Envelope is the machine-readable form of a Result used by the json and
+// yaml renderers.
+type Envelope struct {
+	Request    EnvelopeRequest   `json:"request"`
+	Status     string            `json:"status"`
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers"`
+	DurationMS int64             `json:"duration_ms"`
+	Body       interface{}       `json:"body"`
+}
+
+// EnvelopeRequest describes the request that was sent.
+type EnvelopeRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// NewEnvelope builds the envelope for r. JSON bodies are embedded as JSON
+// values, anything else as a string. Credential request headers are shown
+// as "(set)", so envelopes can end up in CI logs.
+func NewEnvelope(r *Result) Envelope {
+	return Envelope{
+		Request: EnvelopeRequest{
+			Method:  r.Method,
+			URL:     r.URL,
+			Headers: redactHeaders(flattenHeaders(r.RequestHeaders)),
+			Body:    bodyValue(r.RequestBody),
+		},
+		Status:     r.Status,
+		StatusCode: r.StatusCode,
+		Headers:    flattenHeaders(r.Headers),
+		DurationMS: r.Duration.Milliseconds(),
+		Body:       bodyValue(r.Body),
+	}
+}
+
+func flattenHeaders(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+// redactHeaders replaces the values of credential headers with "(set)".
+func redactHeaders(h map[string]string) map[string]string {
+	for k := range h {
+		if IsSecretHeader(k) {
+			h[k] = "(set)"
+		}
+	}
+	return h
+}
+
+// IsSecretHeader reports whether a header carries credentials: Cookie, or
+// a name mentioning auth, a key, token, secret or password (Authorization,
+// X-API-Key, X-Auth-Token, ...).
+func IsSecretHeader(name string) bool {
+	lower := strings.ToLower(name)
+	if lower == "cookie" {
+		return true
+	}
+	for _, word := range []string{"auth", "key", "token", "secret", "password"} {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyValue returns the body as raw JSON if it is valid JSON, as a string
+// otherwise, or nil when empty.
+func bodyValue(body []byte) interface{} {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	return string(body)
+}
+
+// decodeBody decodes a JSON body for the tabular renderers; non-JSON bodies
+// are returned as a string.
+func decodeBody(body []byte) interface{} {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	return v
+}
+
+// SortedHeaderKeys returns the header names in alphabetical order.
+func SortedHeaderKeys(h http.Header) []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
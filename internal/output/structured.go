@@ -0,0 +1,35 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"go-rest-api-cli-demo/internal/yaml"
+)
+
+// JSON writes the result as a single JSON envelope (request, status,
+// headers, timing and body).
+type JSON struct {
+	Pretty bool
+}
+
+func (j JSON) Render(w io.Writer, r *Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if j.Pretty {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(NewEnvelope(r))
+}
+
+// YAML writes the same envelope as JSON, in YAML.
+type YAML struct{}
+
+func (YAML) Render(w io.Writer, r *Result) error {
+	data, err := yaml.Marshal(NewEnvelope(r))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
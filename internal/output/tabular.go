@@ -0,0 +1,133 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Table prints the status line and the JSON body as an aligned table: an
+// array of objects becomes one row per element, an object becomes
+// KEY/VALUE rows.
+type Table struct{}
+
+func (Table) Render(w io.Writer, r *Result) error {
+	fmt.Fprintf(w, "Status: %s (%d ms)\n\n", r.Status, r.Duration.Milliseconds())
+
+	header, rows := tabulate(decodeBody(r.Body))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if len(header) > 0 {
+		upper := make([]string, len(header))
+		for i, h := range header {
+			upper[i] = strings.ToUpper(h)
+		}
+		fmt.Fprintln(tw, strings.Join(upper, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// CSV writes only the tabulated body (header row first), for spreadsheets
+// and scripts.
+type CSV struct{}
+
+func (CSV) Render(w io.Writer, r *Result) error {
+	header, rows := tabulate(decodeBody(r.Body))
+	cw := csv.NewWriter(w)
+	if len(header) > 0 {
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tabulate turns a decoded body into a header and rows.
+func tabulate(body interface{}) ([]string, [][]string) {
+	switch v := body.(type) {
+	case []interface{}:
+		if cols := objectColumns(v); cols != nil {
+			rows := make([][]string, 0, len(v))
+			for _, item := range v {
+				obj := item.(map[string]interface{})
+				row := make([]string, len(cols))
+				for i, c := range cols {
+					row[i] = cell(obj[c])
+				}
+				rows = append(rows, row)
+			}
+			return cols, rows
+		}
+		rows := make([][]string, 0, len(v))
+		for _, item := range v {
+			rows = append(rows, []string{cell(item)})
+		}
+		return []string{"value"}, rows
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		rows := make([][]string, 0, len(keys))
+		for _, k := range keys {
+			rows = append(rows, []string{k, cell(v[k])})
+		}
+		return []string{"key", "value"}, rows
+	case nil:
+		return nil, nil
+	}
+	return []string{"value"}, [][]string{{cell(body)}}
+}
+
+// objectColumns returns the sorted union of keys when every element is an
+// object, or nil otherwise.
+func objectColumns(items []interface{}) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for k := range obj {
+			seen[k] = true
+		}
+	}
+	cols := make([]string, 0, len(seen))
+	for k := range seen {
+		cols = append(cols, k)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// cell renders a value for a table cell: strings as-is, nested values as
+// compact JSON.
+func cell(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,30 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Text is the human-readable default: status, headers and body, or only the
+// body with --raw / --json-only.
+type Text struct {
+	Options
+}
+
+func (t Text) Render(w io.Writer, r *Result) error {
+	// json-only overrides raw if both set
+	if t.JSONOnly || t.Raw {
+		_, err := fmt.Fprintln(w, string(r.Body))
+		return err
+	}
+
+	fmt.Fprintln(w, "\n=== Response ===")
+	fmt.Fprintf(w, "Status: %s\n", r.Status)
+	for _, k := range SortedHeaderKeys(r.Headers) {
+		fmt.Fprintf(w, "%s: %s\n", k, strings.Join(r.Headers[k], ", "))
+	}
+	fmt.Fprintln(w)
+	_, err := fmt.Fprintln(w, string(r.Body))
+	return err
+}
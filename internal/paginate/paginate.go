@@ -0,0 +1,226 @@
+package paginate
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go-rest-api-cli-demo/internal/jsonpath"
+)
+
+// Strategy decides how to get from one page to the next.
+type Strategy interface {
+	// Prepare adjusts the URL of the first request (e.g. adds a page size).
+	Prepare(u *url.URL)
+	// Next returns the URL of the next page, or "" when there are no more
+	// pages. doc is the decoded page body and items the number of items
+	// found on it.
+	Next(current *url.URL, header http.Header, doc interface{}, items int) (string, error)
+}
+
+// Options configures the strategies.
+type Options struct {
+	CursorPath    string // cursor: JSON path of the next cursor in the body
+	CursorParam   string // cursor: query parameter that carries it
+	PageParam     string // page/offset: query parameter of the counter
+	PageStart     int    // page: number of the first page
+	PageSize      int    // page/offset: items per page (0 = server default)
+	PageSizeParam string // page/offset: query parameter for PageSize
+}
+
+// Kinds lists the accepted --paginate values.
+const Kinds = "link|cursor|page|offset"
+
+// New returns the strategy for a --paginate value.
+func New(kind string, opts Options) (Strategy, error) {
+	switch strings.ToLower(kind) {
+	case "link":
+		return Link{}, nil
+	case "cursor":
+		if opts.CursorPath == "" {
+			return nil, fmt.Errorf("--paginate cursor needs --cursor-path")
+		}
+		if opts.CursorParam == "" {
+			opts.CursorParam = "cursor"
+		}
+		return &Cursor{Path: opts.CursorPath, Param: opts.CursorParam}, nil
+	case "page":
+		if opts.PageParam == "" {
+			opts.PageParam = "page"
+		}
+		return Counter{Param: opts.PageParam, Start: opts.PageStart, Step: 1, Size: opts.PageSize, SizeParam: opts.PageSizeParam}, nil
+	case "offset":
+		if opts.PageParam == "" {
+			opts.PageParam = "offset"
+		}
+		return Counter{Param: opts.PageParam, Offset: true, Size: opts.PageSize, SizeParam: opts.PageSizeParam}, nil
+	}
+	return nil, fmt.Errorf("unknown pagination strategy %q (want %s)", kind, Kinds)
+}
+
+// Link follows RFC 5988 `Link: <...>; rel="next"` headers.
+type Link struct{}
+
+func (Link) Prepare(*url.URL) {}
+
+func (Link) Next(current *url.URL, header http.Header, _ interface{}, _ int) (string, error) {
+	for _, v := range header.Values("Link") {
+		next, ok := ParseLinks(v)["next"]
+		if !ok {
+			continue
+		}
+		ref, err := url.Parse(next)
+		if err != nil {
+			return "", fmt.Errorf("invalid next link %q: %w", next, err)
+		}
+		return current.ResolveReference(ref).String(), nil
+	}
+	return "", nil
+}
+
+// ParseLinks parses a Link header value into rel -> URL.
+func ParseLinks(header string) map[string]string {
+	links := map[string]string{}
+	for _, part := range splitLinks(header) {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, "<") {
+			continue
+		}
+		end := strings.Index(part, ">")
+		if end < 0 {
+			continue
+		}
+		target := part[1:end]
+		for _, param := range strings.Split(part[end+1:], ";") {
+			key, val, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(val), `"`)) {
+				links[strings.ToLower(rel)] = target
+			}
+		}
+	}
+	return links
+}
+
+// splitLinks splits on commas that are outside <...>.
+func splitLinks(s string) []string {
+	var (
+		parts []string
+		depth int
+		last  int
+	)
+	for i, c := range s {
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(parts, s[last:])
+}
+
+// Cursor reads the next cursor from the body and passes it as a query
+// parameter. A null or empty cursor ends pagination, and so does a missing
+// one once the path has been found on an earlier page; a path that does
+// not resolve on the first page is an error (most likely a typo).
+type Cursor struct {
+	Path  string
+	Param string
+
+	found bool
+}
+
+func (*Cursor) Prepare(*url.URL) {}
+
+func (c *Cursor) Next(current *url.URL, _ http.Header, doc interface{}, _ int) (string, error) {
+	v, err := jsonpath.Get(doc, c.Path)
+	if err != nil {
+		if c.found {
+			return "", nil // the last page may leave the cursor out
+		}
+		return "", fmt.Errorf("--cursor-path: %w", err)
+	}
+	c.found = true
+	if v == nil {
+		return "", nil
+	}
+	var cursor string
+	switch x := v.(type) {
+	case string:
+		cursor = x
+	case float64:
+		cursor = strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return "", nil
+	default:
+		return "", fmt.Errorf("cursor at %s is not a string or number", c.Path)
+	}
+	if cursor == "" {
+		return "", nil
+	}
+	return withParam(current, c.Param, cursor), nil
+}
+
+// Counter increments a page number (by one) or an offset (by the number of
+// items received). An empty page, or a short page when Size is set, ends
+// pagination.
+type Counter struct {
+	Param     string
+	Start     int
+	Step      int
+	Offset    bool
+	Size      int
+	SizeParam string
+}
+
+// Prepare sends the start page (or offset) with the first request, unless
+// the URL already names one, and the page size when set.
+func (c Counter) Prepare(u *url.URL) {
+	q := u.Query()
+	if q.Get(c.Param) == "" {
+		q.Set(c.Param, strconv.Itoa(c.Start))
+	}
+	if c.Size > 0 && c.SizeParam != "" {
+		q.Set(c.SizeParam, strconv.Itoa(c.Size))
+	}
+	u.RawQuery = q.Encode()
+}
+
+func (c Counter) Next(current *url.URL, _ http.Header, _ interface{}, items int) (string, error) {
+	if items == 0 || c.Size > 0 && items < c.Size {
+		return "", nil
+	}
+
+	cur := c.Start
+	if raw := current.Query().Get(c.Param); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return "", fmt.Errorf("query parameter %s=%q is not a number", c.Param, raw)
+		}
+		cur = n
+	}
+
+	next := cur + c.Step
+	if c.Offset {
+		next = cur + items
+	}
+	return withParam(current, c.Param, strconv.Itoa(next)), nil
+}
+
+func withParam(u *url.URL, key, value string) string {
+	cp := *u
+	q := cp.Query()
+	q.Set(key, value)
+	cp.RawQuery = q.Encode()
+	return cp.String()
+}
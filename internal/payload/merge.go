@@ -0,0 +1,138 @@
+package payload
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Merger combines a base body (e.g. --json-file) with an override (e.g.
+// --data). Bodies are decoded JSON values: objects, arrays or scalars.
+// Inputs are never modified.
+type Merger interface {
+	Merge(base, override interface{}) interface{}
+}
+
+// MergeKinds lists the accepted --merge values.
+const MergeKinds = "shallow|deep|patch"
+
+// ArrayModes lists the accepted --merge-arrays values.
+const ArrayModes = "replace|append"
+
+// NewMerger returns the merge strategy for a --merge value. arrays tells
+// what happens when two arrays meet: "replace" (the override wins) or
+// "append" (base items followed by override items).
+func NewMerger(kind, arrays string) (Merger, error) {
+	var appendArrays bool
+	switch strings.ToLower(arrays) {
+	case "", "replace":
+	case "append":
+		appendArrays = true
+	default:
+		return nil, fmt.Errorf("unknown array merge mode %q (want %s)", arrays, ArrayModes)
+	}
+
+	switch strings.ToLower(kind) {
+	case "", "shallow":
+		return Shallow{AppendArrays: appendArrays}, nil
+	case "deep":
+		return Deep{AppendArrays: appendArrays}, nil
+	case "patch":
+		if appendArrays {
+			return nil, fmt.Errorf("merge patch always replaces arrays (RFC 7386); drop --merge-arrays append")
+		}
+		return Patch{}, nil
+	}
+	return nil, fmt.Errorf("unknown merge strategy %q (want %s)", kind, MergeKinds)
+}
+
+// Shallow overwrites top-level keys: a key given in the override replaces
+// the whole value of the base.
+type Shallow struct {
+	AppendArrays bool
+}
+
+func (s Shallow) Merge(base, override interface{}) interface{} {
+	b, ok1 := base.(map[string]interface{})
+	o, ok2 := override.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return mergeLeaf(base, override, s.AppendArrays)
+	}
+	out := copyObject(b)
+	for k, v := range o {
+		if cur, exists := out[k]; exists {
+			out[k] = mergeLeaf(cur, v, s.AppendArrays)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Deep merges objects recursively, so overriding one nested field keeps its
+// siblings. A null in the override sets the field to null.
+type Deep struct {
+	AppendArrays bool
+}
+
+func (d Deep) Merge(base, override interface{}) interface{} {
+	b, ok1 := base.(map[string]interface{})
+	o, ok2 := override.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return mergeLeaf(base, override, d.AppendArrays)
+	}
+	out := copyObject(b)
+	for k, v := range o {
+		if cur, exists := out[k]; exists {
+			out[k] = d.Merge(cur, v)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Patch applies the override as a JSON Merge Patch (RFC 7386): objects are
+// merged recursively, null removes a field and anything else replaces the
+// target value.
+type Patch struct{}
+
+func (Patch) Merge(base, override interface{}) interface{} {
+	o, ok := override.(map[string]interface{})
+	if !ok {
+		return override
+	}
+	b, ok := base.(map[string]interface{})
+	if !ok {
+		b = nil // a non-object target is replaced by an object
+	}
+	out := copyObject(b)
+	for k, v := range o {
+		if v == nil {
+			delete(out, k)
+			continue
+		}
+		out[k] = Patch{}.Merge(out[k], v)
+	}
+	return out
+}
+
+// mergeLeaf merges two values that are not both objects.
+func mergeLeaf(base, override interface{}, appendArrays bool) interface{} {
+	if appendArrays {
+		b, ok1 := base.([]interface{})
+		o, ok2 := override.([]interface{})
+		if ok1 && ok2 {
+			out := make([]interface{}, 0, len(b)+len(o))
+			return append(append(out, b...), o...)
+		}
+	}
+	return override
+}
+
+func copyObject(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
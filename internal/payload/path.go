@@ -0,0 +1,144 @@
+package payload
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a body path: an object key, an array index,
+// or an append ("[]").
+type pathSegment struct {
+	key    string
+	index  int
+	isIdx  bool
+	append bool
+}
+
+// parsePath splits "user.address.city", "tags[]" or "items[0].id" into
+// segments. A backslash escapes '.', '[' and itself.
+func parsePath(path string) ([]pathSegment, error) {
+	var (
+		segs []pathSegment
+		cur  strings.Builder
+		have bool
+	)
+	flush := func() {
+		if have {
+			segs = append(segs, pathSegment{key: cur.String()})
+		}
+		cur.Reset()
+		have = false
+	}
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\' && i+1 < len(path):
+			i++
+			cur.WriteByte(path[i])
+			have = true
+		case c == '.':
+			if !have && (len(segs) == 0 || !segs[len(segs)-1].isIdx && !segs[len(segs)-1].append) {
+				return nil, fmt.Errorf("invalid path %q: empty key", path)
+			}
+			flush()
+		case c == '[':
+			flush()
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", path)
+			}
+			inner := path[i+1 : i+end]
+			i += end
+			if inner == "" {
+				segs = append(segs, pathSegment{append: true})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid path %q: bad index [%s]", path, inner)
+			}
+			segs = append(segs, pathSegment{index: n, isIdx: true})
+		default:
+			cur.WriteByte(c)
+			have = true
+		}
+	}
+	flush()
+	if len(segs) == 0 || segs[0].isIdx || segs[0].append {
+		return nil, fmt.Errorf("invalid path %q: must start with a key", path)
+	}
+	return segs, nil
+}
+
+// SetPath sets value at path inside root, creating objects and arrays on
+// the way ("user.address.city", "tags[]" appends, "items[0].id").
+func SetPath(root map[string]interface{}, path string, value interface{}) error {
+	segs, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	var cur interface{} = root
+	set := func(v interface{}) {} // replaces the container we are in
+	for i, s := range segs {
+		last := i == len(segs)-1
+		next := func() interface{} {
+			if segs[i+1].isIdx || segs[i+1].append {
+				return []interface{}{}
+			}
+			return map[string]interface{}{}
+		}
+
+		switch {
+		case !s.isIdx && !s.append:
+			obj, ok := cur.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("path %q: %q is not an object", path, s.key)
+			}
+			if last {
+				obj[s.key] = value
+				return nil
+			}
+			child, exists := obj[s.key]
+			if !exists || child == nil {
+				child = next()
+				obj[s.key] = child
+			}
+			key := s.key
+			set = func(v interface{}) { obj[key] = v }
+			cur = child
+		default:
+			arr, ok := cur.([]interface{})
+			if !ok {
+				return fmt.Errorf("path %q: not an array before [%s]", path, indexText(s))
+			}
+			idx := s.index
+			if s.append {
+				idx = len(arr)
+			}
+			for len(arr) <= idx {
+				arr = append(arr, nil)
+			}
+			set(arr) // the slice may have grown
+			if last {
+				arr[idx] = value
+				return nil
+			}
+			if arr[idx] == nil {
+				arr[idx] = next()
+			}
+			a, j := arr, idx
+			set = func(v interface{}) { a[j] = v }
+			cur = arr[idx]
+		}
+	}
+	return nil
+}
+
+func indexText(s pathSegment) string {
+	if s.append {
+		return ""
+	}
+	return strconv.Itoa(s.index)
+}
@@ -0,0 +1,45 @@
+package payload
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ReadArg resolves curl-style body arguments: "@-" reads stdin, "@path"
+// reads a file, anything else is returned unchanged.
+func ReadArg(arg string, stdin io.Reader) (string, bool, error) {
+	if !strings.HasPrefix(arg, "@") {
+		return arg, false, nil
+	}
+	src := arg[1:]
+	var (
+		data []byte
+		err  error
+	)
+	if src == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(src)
+	}
+	if err != nil {
+		return "", true, fmt.Errorf("read %s: %w", arg, err)
+	}
+	return string(data), true, nil
+}
+
+// EncodeForm builds an application/x-www-form-urlencoded body from
+// "name=value" pairs, keeping their order (url.Values would sort them).
+func EncodeForm(pairs []string) (string, error) {
+	parts := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok || name == "" {
+			return "", fmt.Errorf("invalid form field %q, expected 'name=value'", p)
+		}
+		parts = append(parts, url.QueryEscape(name)+"="+url.QueryEscape(value))
+	}
+	return strings.Join(parts, "&"), nil
+}
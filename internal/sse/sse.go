@@ -0,0 +1,103 @@
+// Package sse parses text/event-stream bodies incrementally, following the
+// WHATWG Server-Sent Events format.
+package sse
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is one dispatched server-sent event.
+type Event struct {
+	ID    string
+	Event string // "message" when the stream did not name it
+	Data  string
+	// Retry is the reconnection delay the server asked for (0 = not sent).
+	Retry time.Duration
+}
+
+// Reader reads events from a stream as they arrive.
+type Reader struct {
+	r *bufio.Reader
+	// lastID persists across events, as the spec requires.
+	lastID string
+}
+
+// NewReader wraps an event-stream body.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// LastID is the last event ID seen on the stream (for Last-Event-ID).
+func (r *Reader) LastID() string { return r.lastID }
+
+// Next blocks until the next event is complete. It returns io.EOF when the
+// stream ends; a partially received event at the end is discarded.
+func (r *Reader) Next() (*Event, error) {
+	var (
+		data     strings.Builder
+		hasData  bool
+		name     string
+		retry    time.Duration
+		hasRetry bool
+	)
+
+	for {
+		line, err := r.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			// Blank line: dispatch, unless only id/retry fields were seen
+			if !hasData {
+				if hasRetry {
+					return &Event{ID: r.lastID, Retry: retry}, nil
+				}
+				if err == io.EOF {
+					return nil, io.EOF
+				}
+				name = ""
+				continue
+			}
+			if name == "" {
+				name = "message"
+			}
+			return &Event{ID: r.lastID, Event: name, Data: data.String(), Retry: retry}, nil
+		}
+		if err == io.EOF {
+			// Incomplete event without its terminating blank line
+			return nil, io.EOF
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			name = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				r.lastID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				retry = time.Duration(ms) * time.Millisecond
+				hasRetry = true
+			}
+		}
+	}
+}
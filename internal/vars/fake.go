@@ -0,0 +1,95 @@
+package vars
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Word lists for the fake.* functions. Email addresses use the reserved
+// example.* domains, so fixtures never reach a real mailbox.
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Grace", "Linus", "Margaret", "Dennis", "Barbara", "Ken",
+		"Frances", "Edsger", "Radia", "Donald", "Hedy", "Tim", "Katherine", "John",
+		"Sophie", "Lucas", "Emma", "Noah", "Mia", "Leon", "Chloe", "Hugo",
+	}
+	lastNames = []string{
+		"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Ritchie", "Liskov",
+		"Thompson", "Allen", "Dijkstra", "Perlman", "Knuth", "Lamarr", "Berners-Lee",
+		"Johnson", "Backus", "Martin", "Bernard", "Dubois", "Moreau", "Fischer",
+		"Schmidt", "Rossi", "Garcia",
+	}
+	streets = []string{
+		"Main Street", "Oak Avenue", "Maple Road", "Station Road", "High Street",
+		"Church Lane", "Park Avenue", "Mill Lane", "River Road", "King Street",
+	}
+	cities = []string{
+		"Paris", "Berlin", "Madrid", "Rome", "Lisbon", "Amsterdam", "Vienna",
+		"Dublin", "Prague", "Oslo", "Boston", "Denver", "Toronto", "Sydney",
+	}
+	countries = []string{
+		"France", "Germany", "Spain", "Italy", "Portugal", "Netherlands", "Austria",
+		"Ireland", "Czechia", "Norway", "United States", "Canada", "Australia",
+	}
+	companyWords = []string{
+		"Acme", "Globex", "Initech", "Umbrella", "Stark", "Wayne", "Hooli",
+		"Vandelay", "Soylent", "Tyrell", "Cyberdyne", "Wonka",
+	}
+	companySuffixes = []string{"Inc", "Ltd", "GmbH", "SA", "LLC", "Group"}
+	emailDomains    = []string{"example.com", "example.org", "example.net"}
+)
+
+// fake adapts a generator without arguments to a Func.
+func fake(gen func() string) Func {
+	return func(args []string) (string, error) {
+		if len(args) > 0 {
+			return "", fmt.Errorf("takes no arguments")
+		}
+		return gen(), nil
+	}
+}
+
+func pick(list []string) string {
+	return list[randN(int64(len(list)))]
+}
+
+func digits(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + randN(10))
+	}
+	return string(b)
+}
+
+func fakeName() string {
+	return pick(firstNames) + " " + pick(lastNames)
+}
+
+func fakeUsername() string {
+	return strings.ToLower(pick(firstNames)+"_"+strings.ReplaceAll(pick(lastNames), "-", "")) + digits(2)
+}
+
+func fakeEmail() string {
+	local := strings.ToLower(pick(firstNames) + "." + strings.ReplaceAll(pick(lastNames), "-", ""))
+	return local + digits(3) + "@" + pick(emailDomains)
+}
+
+func fakePhone() string {
+	return "+1-555-" + digits(3) + "-" + digits(4) // 555 numbers are fictional
+}
+
+func fakeStreet() string {
+	return fmt.Sprintf("%d %s", 1+randN(199), pick(streets))
+}
+
+func fakeZip() string {
+	return digits(5)
+}
+
+func fakeAddress() string {
+	return fmt.Sprintf("%s, %s %s, %s", fakeStreet(), fakeZip(), pick(cities), pick(countries))
+}
+
+func fakeCompany() string {
+	return pick(companyWords) + " " + pick(companySuffixes)
+}
@@ -0,0 +1,308 @@
+package vars
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Func is a template function. args are the literal arguments of the call;
+// a value piped in with "|" is appended as the last argument.
+type Func func(args []string) (string, error)
+
+// funcs are the functions available in {{...}} placeholders. They are
+// evaluated for every placeholder, so each request gets fresh values.
+var funcs = map[string]Func{
+	"uuid":       fnUUID,
+	"now":        fnNow,
+	"timestamp":  fnTimestamp,
+	"randInt":    fnRandInt,
+	"randString": fnRandString,
+	"base64":     fnBase64,
+	"sha256":     fnSHA256,
+	"json":       fnJSON,
+	"env":        fnEnv,
+
+	"fake.firstName": fake(func() string { return pick(firstNames) }),
+	"fake.lastName":  fake(func() string { return pick(lastNames) }),
+	"fake.name":      fake(fakeName),
+	"fake.username":  fake(fakeUsername),
+	"fake.email":     fake(fakeEmail),
+	"fake.phone":     fake(fakePhone),
+	"fake.street":    fake(fakeStreet),
+	"fake.city":      fake(func() string { return pick(cities) }),
+	"fake.zip":       fake(fakeZip),
+	"fake.country":   fake(func() string { return pick(countries) }),
+	"fake.address":   fake(fakeAddress),
+	"fake.company":   fake(fakeCompany),
+}
+
+// Eval evaluates a function expression such as "uuid", "randInt 1 10",
+// "now '2006-01-02'", "fake.email" or "token | sha256". A bare name at the
+// start of a pipeline is looked up with get first, so variables win over
+// functions of the same name. ok is false when the expression is neither a
+// variable nor a known function.
+func Eval(expr string, get LookupFunc) (string, bool, error) {
+	stages, err := parsePipeline(expr)
+	if err != nil {
+		return "", false, err
+	}
+
+	var (
+		value string
+		piped bool
+	)
+	for i, words := range stages {
+		first := words[0]
+		if i == 0 && len(words) == 1 {
+			if first.quoted {
+				value, piped = first.text, true
+				continue
+			}
+			if v, ok, err := get(first.text); err != nil || ok {
+				if err != nil {
+					return "", false, err
+				}
+				value, piped = v, true
+				continue
+			}
+		}
+
+		fn, ok := funcs[first.text]
+		if !ok || first.quoted {
+			if i == 0 {
+				return "", false, nil
+			}
+			return "", false, fmt.Errorf("unknown function %q", first.text)
+		}
+		args := make([]string, 0, len(words))
+		for _, w := range words[1:] {
+			args = append(args, w.text)
+		}
+		if piped {
+			args = append(args, value)
+		}
+		if value, err = fn(args); err != nil {
+			return "", false, fmt.Errorf("%s: %w", first.text, err)
+		}
+		piped = true
+	}
+	return value, true, nil
+}
+
+// word is one argument of a pipeline stage.
+type word struct {
+	text   string
+	quoted bool
+}
+
+// parsePipeline splits an expression into stages separated by "|", each a
+// list of words. Words may be quoted with '...' or "..." (handy inside JSON
+// strings).
+func parsePipeline(expr string) ([][]word, error) {
+	var (
+		stages [][]word
+		cur    []word
+	)
+	endStage := func() error {
+		if len(cur) == 0 {
+			return fmt.Errorf("empty expression in %q", expr)
+		}
+		stages = append(stages, cur)
+		cur = nil
+		return nil
+	}
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '|':
+			if err := endStage(); err != nil {
+				return nil, err
+			}
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in %q", expr)
+			}
+			cur = append(cur, word{text: expr[i+1 : i+1+end], quoted: true})
+			i += end + 2
+		default:
+			j := i
+			for j < len(expr) && !strings.ContainsRune(" \t|'\"", rune(expr[j])) {
+				j++
+			}
+			cur = append(cur, word{text: expr[i:j]})
+			i = j
+		}
+	}
+	if err := endStage(); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+func wantArgs(args []string, min, max int, usage string) error {
+	if len(args) < min || len(args) > max {
+		return fmt.Errorf("usage: %s", usage)
+	}
+	return nil
+}
+
+// UUID returns a random (version 4) UUID.
+func UUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func fnUUID(args []string) (string, error) {
+	if err := wantArgs(args, 0, 0, "uuid"); err != nil {
+		return "", err
+	}
+	return UUID(), nil
+}
+
+// fnNow formats the current UTC time: RFC 3339 by default, or "unix",
+// "unixms", "date" or a Go layout such as '2006-01-02 15:04'.
+func fnNow(args []string) (string, error) {
+	if err := wantArgs(args, 0, 1, "now [rfc3339|unix|unixms|date|LAYOUT]"); err != nil {
+		return "", err
+	}
+	t := time.Now().UTC()
+	layout := "rfc3339"
+	if len(args) == 1 {
+		layout = args[0]
+	}
+	switch strings.ToLower(layout) {
+	case "rfc3339":
+		return t.Format(time.RFC3339), nil
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10), nil
+	case "unixms":
+		return strconv.FormatInt(t.UnixMilli(), 10), nil
+	case "date":
+		return t.Format(time.DateOnly), nil
+	}
+	return t.Format(layout), nil
+}
+
+func fnTimestamp(args []string) (string, error) {
+	if err := wantArgs(args, 0, 0, "timestamp"); err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(time.Now().Unix(), 10), nil
+}
+
+// fnRandInt returns a random integer in MIN..MAX (both included).
+func fnRandInt(args []string) (string, error) {
+	if err := wantArgs(args, 2, 2, "randInt MIN MAX"); err != nil {
+		return "", err
+	}
+	lo, err1 := strconv.ParseInt(args[0], 10, 64)
+	hi, err2 := strconv.ParseInt(args[1], 10, 64)
+	if err1 != nil || err2 != nil || hi < lo {
+		return "", fmt.Errorf("usage: randInt MIN MAX (integers, MIN <= MAX)")
+	}
+	n, err := RandInt(lo, hi)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(n, 10), nil
+}
+
+// RandInt returns a uniform random integer in lo..hi (both included). The
+// range is computed with big.Int, so it may span all of int64.
+func RandInt(lo, hi int64) (int64, error) {
+	if hi < lo {
+		return 0, fmt.Errorf("MIN must not be greater than MAX")
+	}
+	span := new(big.Int).Sub(big.NewInt(hi), big.NewInt(lo))
+	n, err := rand.Int(rand.Reader, span.Add(span, big.NewInt(1)))
+	if err != nil {
+		return 0, err
+	}
+	return n.Add(n, big.NewInt(lo)).Int64(), nil
+}
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func fnRandString(args []string) (string, error) {
+	if err := wantArgs(args, 1, 1, "randString LENGTH"); err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 0 || n > 4096 {
+		return "", fmt.Errorf("usage: randString LENGTH (0-4096)")
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphanumeric[randN(int64(len(alphanumeric)))]
+	}
+	return string(b), nil
+}
+
+func fnBase64(args []string) (string, error) {
+	if err := wantArgs(args, 1, 1, "base64 TEXT (or VALUE | base64)"); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(args[0])), nil
+}
+
+// fnJSON quotes its argument as a JSON string, so values with quotes or
+// backslashes stay valid inside a JSON body.
+func fnJSON(args []string) (string, error) {
+	if err := wantArgs(args, 1, 1, "json TEXT (or VALUE | json)"); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(args[0])
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func fnSHA256(args []string) (string, error) {
+	if err := wantArgs(args, 1, 1, "sha256 TEXT (or VALUE | sha256)"); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(args[0]))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// fnEnv reads an environment variable; without a default an unset
+// variable is an error.
+func fnEnv(args []string) (string, error) {
+	if err := wantArgs(args, 1, 2, "env NAME [DEFAULT]"); err != nil {
+		return "", err
+	}
+	if v, ok := os.LookupEnv(args[0]); ok {
+		return v, nil
+	}
+	if len(args) == 2 {
+		return args[1], nil
+	}
+	return "", fmt.Errorf("environment variable %s is not set", args[0])
+}
+
+// randN returns a uniform random number in [0, n).
+func randN(n int64) int64 {
+	v, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		return 0
+	}
+	return v.Int64()
+}
@@ -0,0 +1,107 @@
+package vars
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// placeholder matches {{expr}}; a backslash before it (\{{expr}}) escapes
+// it, which leaves {{expr}} in the output as literal text.
+var placeholder = regexp.MustCompile(`\\?\{\{\s*(.*?)\s*\}\}`)
+
+// LookupFunc resolves the text between {{ and }} (trimmed). It reports
+// ok=false when the expression is unknown.
+type LookupFunc func(expr string) (value string, ok bool, err error)
+
+// Expand replaces {{name}} placeholders in s with values from vars, or
+// with the result of a function expression (see Eval).
+// It returns an error listing every placeholder that has no value.
+func Expand(s string, vars map[string]string) (string, error) {
+	get := func(name string) (string, bool, error) {
+		v, ok := vars[name]
+		return v, ok, nil
+	}
+	return ExpandFunc(s, func(expr string) (string, bool, error) {
+		if v, ok := vars[expr]; ok {
+			return v, true, nil
+		}
+		return Eval(expr, get)
+	})
+}
+
+// ExpandFunc replaces every {{expr}} placeholder in s with the value returned
+// by lookup. Unknown expressions are collected into a single error.
+// \{{expr}} is kept as the literal text {{expr}}.
+func ExpandFunc(s string, lookup LookupFunc) (string, error) {
+	var (
+		missing  = map[string]bool{}
+		firstErr error
+	)
+	out := placeholder.ReplaceAllStringFunc(s, func(m string) string {
+		if m[0] == '\\' {
+			return m[1:]
+		}
+		expr := placeholder.FindStringSubmatch(m)[1]
+		v, ok, err := lookup(expr)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("{{%s}}: %w", expr, err)
+			}
+			return m
+		}
+		if !ok {
+			missing[expr] = true
+			return m
+		}
+		return v
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for n := range missing {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("undefined variable(s): %s", strings.Join(names, ", "))
+	}
+	return out, nil
+}
+
+// DefaultFile is the variables file used when none is given.
+const DefaultFile = ".rest-vars.json"
+
+// LoadFile reads a JSON object of string variables. A missing file yields
+// an empty map.
+func LoadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	m := map[string]string{}
+	if strings.TrimSpace(string(data)) == "" {
+		return m, nil
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// SaveFile writes variables as an indented JSON object.
+func SaveFile(path string, m map[string]string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
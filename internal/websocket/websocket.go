@@ -0,0 +1,333 @@
+// Package websocket is a small RFC 6455 client. The opening handshake is an
+// ordinary HTTP request (so profiles, auth and TLS settings apply); this
+// package only prepares its headers, checks the 101 answer and speaks the
+// frame protocol on the upgraded connection.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Opcode is the frame type.
+type Opcode byte
+
+const (
+	OpContinuation Opcode = 0x0
+	OpText         Opcode = 0x1
+	OpBinary       Opcode = 0x2
+	OpClose        Opcode = 0x8
+	OpPing         Opcode = 0x9
+	OpPong         Opcode = 0xA
+)
+
+func (o Opcode) String() string {
+	switch o {
+	case OpText:
+		return "text"
+	case OpBinary:
+		return "binary"
+	case OpClose:
+		return "close"
+	case OpPing:
+		return "ping"
+	case OpPong:
+		return "pong"
+	}
+	return fmt.Sprintf("opcode-%d", byte(o))
+}
+
+// Close status codes used by the client.
+const (
+	CloseNormal        = 1000
+	CloseNoStatus      = 1005
+	CloseProtocolError = 1002
+)
+
+// maxMessageSize guards against runaway messages.
+const maxMessageSize = 64 << 20
+
+// acceptGUID is the fixed GUID of the Sec-WebSocket-Accept computation.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// CloseError is returned by ReadMessage once the peer closed the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed (%d)", e.Code)
+	}
+	return fmt.Sprintf("websocket closed (%d %s)", e.Code, e.Reason)
+}
+
+// NewKey returns a random Sec-WebSocket-Key.
+func NewKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// HandshakeHeaders are the headers that turn a GET into an upgrade request.
+func HandshakeHeaders(key string, protocols []string) map[string]string {
+	h := map[string]string{
+		"Upgrade":               "websocket",
+		"Connection":            "Upgrade",
+		"Sec-Websocket-Key":     key,
+		"Sec-Websocket-Version": "13",
+	}
+	if len(protocols) > 0 {
+		h["Sec-Websocket-Protocol"] = strings.Join(protocols, ", ")
+	}
+	return h
+}
+
+// acceptKey is the Sec-WebSocket-Accept value expected for key.
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Upgrade checks the handshake response and returns the connection. net/http
+// hands out the raw connection as the body of a 101 response.
+func Upgrade(resp *http.Response, key string) (*Conn, error) {
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		msg := strings.TrimSpace(string(body))
+		if msg != "" {
+			return nil, fmt.Errorf("handshake failed: HTTP %s: %s", resp.Status, msg)
+		}
+		return nil, fmt.Errorf("handshake failed: HTTP %s", resp.Status)
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		resp.Body.Close()
+		return nil, errors.New("handshake failed: missing Upgrade: websocket")
+	}
+	if resp.Header.Get("Sec-Websocket-Accept") != acceptKey(key) {
+		resp.Body.Close()
+		return nil, errors.New("handshake failed: wrong Sec-WebSocket-Accept")
+	}
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("handshake failed: connection is not writable")
+	}
+	return &Conn{rwc: rwc, br: bufio.NewReader(rwc)}, nil
+}
+
+// Conn is an established client connection. Reads must come from a single
+// goroutine; writes may be concurrent.
+type Conn struct {
+	rwc io.ReadWriteCloser
+	br  *bufio.Reader
+
+	wmu    sync.Mutex
+	closed bool // close frame sent
+
+	// OnPong, when set, is called for every pong received.
+	OnPong func(data []byte)
+}
+
+// WriteMessage sends one unfragmented, masked frame.
+func (c *Conn) WriteMessage(op Opcode, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return errors.New("websocket: connection is closing")
+	}
+	if op == OpClose {
+		c.closed = true
+	}
+	return c.writeFrame(op, data)
+}
+
+func (c *Conn) writeFrame(op Opcode, data []byte) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | byte(op) // FIN
+	switch n := len(data); {
+	case n < 126:
+		header[1] = 0x80 | byte(n)
+	case n <= 0xFFFF:
+		header[1] = 0x80 | 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 0x80 | 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	// Client frames are always masked
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	header = append(header, mask[:]...)
+	masked := make([]byte, len(data))
+	for i, b := range data {
+		masked[i] = b ^ mask[i%4]
+	}
+
+	if _, err := c.rwc.Write(append(header, masked...)); err != nil {
+		return fmt.Errorf("websocket write: %w", err)
+	}
+	return nil
+}
+
+// Ping sends a ping frame.
+func (c *Conn) Ping(data []byte) error { return c.WriteMessage(OpPing, data) }
+
+// Close sends a close frame (unless one was sent already) and closes the
+// connection without waiting for the server's answer.
+func (c *Conn) Close(code int, reason string) error {
+	err := c.SendClose(code, reason)
+	c.rwc.Close()
+	return err
+}
+
+// SendClose starts the closing handshake: the server answers with its own
+// close frame, which ReadMessage reports as a *CloseError.
+func (c *Conn) SendClose(code int, reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	payload = append(payload, reason...)
+	return c.WriteMessage(OpClose, payload)
+}
+
+// ReadMessage returns the next text or binary message, reassembling
+// fragments. Pings are answered and pongs reported via OnPong. When the
+// server closes the connection, the close frame is echoed and a *CloseError
+// is returned.
+func (c *Conn) ReadMessage() (Opcode, []byte, error) {
+	var (
+		msgOp Opcode
+		msg   []byte
+		frag  bool
+	)
+	for {
+		fin, op, data, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case OpPing:
+			c.wmu.Lock()
+			if !c.closed {
+				err = c.writeFrame(OpPong, data)
+			}
+			c.wmu.Unlock()
+			if err != nil {
+				return 0, nil, err
+			}
+			continue
+		case OpPong:
+			if c.OnPong != nil {
+				c.OnPong(data)
+			}
+			continue
+		case OpClose:
+			ce := &CloseError{Code: CloseNoStatus}
+			if len(data) >= 2 {
+				ce.Code = int(binary.BigEndian.Uint16(data))
+				ce.Reason = string(data[2:])
+			}
+			c.wmu.Lock()
+			if !c.closed {
+				c.closed = true
+				c.writeFrame(OpClose, data[:min(len(data), 2)])
+			}
+			c.wmu.Unlock()
+			c.rwc.Close()
+			return 0, nil, ce
+		case OpText, OpBinary:
+			if frag {
+				return 0, nil, c.protocolError("new message inside a fragmented one")
+			}
+			msgOp, msg = op, data
+		case OpContinuation:
+			if !frag {
+				return 0, nil, c.protocolError("unexpected continuation frame")
+			}
+			if len(msg)+len(data) > maxMessageSize {
+				return 0, nil, c.protocolError("message too large")
+			}
+			msg = append(msg, data...)
+		default:
+			return 0, nil, c.protocolError(fmt.Sprintf("unknown opcode %d", op))
+		}
+
+		if !fin {
+			frag = true
+			continue
+		}
+		if msgOp == OpText && !utf8.Valid(msg) {
+			return 0, nil, c.protocolError("invalid UTF-8 in text message")
+		}
+		return msgOp, msg, nil
+	}
+}
+
+func (c *Conn) protocolError(msg string) error {
+	c.Close(CloseProtocolError, "")
+	return fmt.Errorf("websocket protocol error: %s", msg)
+}
+
+func (c *Conn) readFrame() (fin bool, op Opcode, data []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	op = Opcode(head[0] & 0x0F)
+	masked := head[1]&0x80 != 0
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxMessageSize {
+		err = c.protocolError("frame too large")
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return
+		}
+	}
+	data = make([]byte, length)
+	if _, err = io.ReadFull(c.br, data); err != nil {
+		return
+	}
+	if masked {
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+	return
+}
@@ -0,0 +1,688 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal decodes one YAML document into JSON-compatible values:
+// map[string]interface{}, []interface{}, string, float64, bool and nil.
+//
+// It covers the YAML used by API specs and test suites: block mappings and
+// sequences, flow collections ([a, b], {a: 1}), plain/quoted scalars,
+// literal (|) and folded (>) block scalars, comments, anchors/aliases and
+// "<<" merge keys. Tags are ignored; complex keys ("? ") are not supported.
+func Unmarshal(data []byte) (interface{}, error) {
+	p := &parser{lines: splitLines(string(data)), anchors: map[string]interface{}{}}
+	v, err := p.parseNode(0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected content %q", p.lines[p.pos].text)
+	}
+	return v, nil
+}
+
+// ToJSON converts a YAML document to JSON.
+func ToJSON(data []byte) ([]byte, error) {
+	v, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// line is one source line.
+type line struct {
+	num    int    // 1-based line number
+	indent int    // leading spaces
+	text   string // content after the indentation, comment removed
+	raw    string // the original line (for block scalars)
+	tab    bool   // the indentation continues with a tab
+}
+
+func splitLines(src string) []line {
+	src = strings.TrimPrefix(src, "\ufeff")
+	var lines []line
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		content := strings.TrimLeft(raw, " ")
+		l := line{num: i + 1, indent: len(raw) - len(content), raw: raw, tab: strings.HasPrefix(content, "\t")}
+		l.text = strings.TrimRight(stripComment(content), " \t")
+		if l.indent == 0 && (l.text == "---" || l.text == "..." || strings.HasPrefix(l.text, "%")) {
+			l.text = "" // document markers and directives
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// stripComment removes a " #" comment that is not inside quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" :[{,-", s[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type parser struct {
+	lines   []line
+	pos     int
+	anchors map[string]interface{}
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	num := len(p.lines)
+	if p.pos < len(p.lines) {
+		num = p.lines[p.pos].num
+	}
+	return fmt.Errorf("yaml: line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+}
+
+// nextLine skips blank lines and returns the next one, or nil at the end.
+// Tabs are not allowed in indentation: YAML would not read "\tb: 1" as
+// nested, so it is an error rather than a silently different document.
+func (p *parser) nextLine() (*line, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	if p.lines[p.pos].tab {
+		return nil, p.errorf("tab character in indentation (use spaces)")
+	}
+	return &p.lines[p.pos], nil
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseNode parses the block node starting at the next non-blank line, if
+// that line is indented at least minIndent.
+func (p *parser) parseNode(minIndent int) (interface{}, error) {
+	next, err := p.nextLine()
+	if err != nil || next == nil || next.indent < minIndent {
+		return nil, err
+	}
+	l := *next
+	if isSeqItem(l.text) {
+		return p.parseSeq(l.indent)
+	}
+	if _, _, ok := splitKey(l.text); ok {
+		return p.parseMap(l.indent)
+	}
+	p.pos++
+	return p.parseValue(l.text, l.indent-1, false)
+}
+
+func (p *parser) parseMap(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for {
+		next, err := p.nextLine()
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			break
+		}
+		l := *next
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		key, rest, ok := splitKey(l.text)
+		if !ok {
+			if isSeqItem(l.text) {
+				return nil, p.errorf("sequence item where a mapping key was expected")
+			}
+			return nil, p.errorf("expected 'key: value', got %q", l.text)
+		}
+		p.pos++
+		v, err := p.parseValue(rest, indent, true)
+		if err != nil {
+			return nil, err
+		}
+		if key == "<<" {
+			if err := mergeInto(m, v); err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			continue
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// mergeInto applies a "<<" merge key: keys already set win.
+func mergeInto(m map[string]interface{}, v interface{}) error {
+	sources := []interface{}{v}
+	if list, ok := v.([]interface{}); ok {
+		sources = list
+	}
+	for _, src := range sources {
+		sm, ok := src.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("<< needs a mapping or a list of mappings")
+		}
+		for k, val := range sm {
+			if _, exists := m[k]; !exists {
+				m[k] = val
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseSeq(indent int) (interface{}, error) {
+	out := []interface{}{}
+	for {
+		l, err := p.nextLine()
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			break
+		}
+		if l.indent < indent || (l.indent == indent && !isSeqItem(l.text)) {
+			break
+		}
+		if l.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		after := l.text[1:]
+		rest := strings.TrimLeft(after, " ")
+		var v interface{}
+		_, _, isKey := splitKey(rest)
+		switch {
+		case rest == "":
+			p.pos++
+			v, err = p.parseNode(indent + 1)
+		case isSeqItem(rest) || (isKey && !strings.HasPrefix(rest, "&")):
+			// "- key: v" or "- - x": the rest is a nested block that
+			// starts at the column after the dash
+			l.indent += 1 + len(after) - len(rest)
+			l.text = rest
+			v, err = p.parseNode(l.indent)
+		default:
+			p.pos++
+			v, err = p.parseValue(rest, indent, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// parseValue parses the value after "key:" or "- " (rest) for a node whose
+// parent is at column indent. sameIndentSeq allows "key:\n- item" lists.
+func (p *parser) parseValue(rest string, indent int, sameIndentSeq bool) (interface{}, error) {
+	rest = strings.TrimSpace(rest)
+
+	var anchor string
+	if strings.HasPrefix(rest, "&") {
+		name, after, _ := strings.Cut(rest[1:], " ")
+		anchor, rest = name, strings.TrimSpace(after)
+	}
+	if strings.HasPrefix(rest, "!") {
+		_, after, _ := strings.Cut(rest, " ") // tags are ignored
+		rest = strings.TrimSpace(after)
+	}
+
+	var (
+		v   interface{}
+		err error
+	)
+	switch {
+	case rest == "":
+		p.skipBlank()
+		if sameIndentSeq && p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSeqItem(p.lines[p.pos].text) {
+			v, err = p.parseSeq(indent)
+		} else {
+			v, err = p.parseNode(indent + 1)
+		}
+	case rest[0] == '*':
+		a, ok := p.anchors[rest[1:]]
+		if !ok {
+			return nil, p.errorf("unknown alias %q", rest)
+		}
+		v = a
+	case rest[0] == '|' || rest[0] == '>':
+		v, err = p.blockScalar(rest, indent)
+	case rest[0] == '[' || rest[0] == '{':
+		v, err = p.flowValue(rest)
+	default:
+		v, err = p.inlineScalar(rest, indent)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if anchor != "" {
+		p.anchors[anchor] = v
+	}
+	return v, nil
+}
+
+// splitKey splits "key: value" (the key may be quoted).
+func splitKey(text string) (key, rest string, ok bool) {
+	if text == "" || strings.IndexByte("[{|>*&!%@`#", text[0]) >= 0 || isSeqItem(text) {
+		return "", "", false
+	}
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text, text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		after := text[end+1:]
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", false
+		}
+		k, err := unquote(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		return k, strings.TrimPrefix(after, ":"), true
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return strings.TrimSpace(text[:i]), text[i+2:], true
+	}
+	if strings.HasSuffix(text, ":") && len(text) > 1 {
+		return strings.TrimSpace(text[:len(text)-1]), "", true
+	}
+	return "", "", false
+}
+
+// closingQuote returns the index of the quote closing s[0], or -1.
+func closingQuote(s string, q byte) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q:
+			if q == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++ // '' is an escaped quote
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	out, err := strconv.Unquote(strings.ReplaceAll(s, `\/`, "/"))
+	if err != nil {
+		return "", fmt.Errorf("bad quoted string %s", s)
+	}
+	return out, nil
+}
+
+// inlineScalar parses a plain or quoted scalar that may continue on more
+// indented lines.
+func (p *parser) inlineScalar(rest string, indent int) (interface{}, error) {
+	if rest[0] == '"' || rest[0] == '\'' {
+		text := rest
+		for closingQuote(text, rest[0]) < 0 {
+			if p.pos >= len(p.lines) {
+				return nil, p.errorf("unterminated quoted string")
+			}
+			next := strings.TrimSpace(p.lines[p.pos].raw)
+			p.pos++
+			if next == "" {
+				text += "\n"
+			} else if strings.HasSuffix(text, "\n") {
+				text += next
+			} else {
+				text += " " + next
+			}
+		}
+		end := closingQuote(text, rest[0])
+		if strings.TrimSpace(text[end+1:]) != "" {
+			return nil, p.errorf("unexpected text after quoted string")
+		}
+		s, err := unquote(text[:end+1])
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		return s, nil
+	}
+
+	// Plain scalars fold their continuation lines with spaces
+	text := rest
+	for p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.text == "" || next.indent <= indent {
+			break
+		}
+		text += " " + next.text
+		p.pos++
+	}
+	return resolvePlain(text), nil
+}
+
+var (
+	intPlain   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPlain = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolvePlain types a plain scalar (YAML 1.2 core schema).
+func resolvePlain(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if intPlain.MatchString(s) || floatPlain.MatchString(s) {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o") {
+		if n, err := strconv.ParseInt(s, 0, 64); err == nil {
+			return float64(n)
+		}
+	}
+	return s
+}
+
+// blockScalar reads a literal (|) or folded (>) scalar whose header is
+// given; its lines are those indented more than the parent.
+func (p *parser) blockScalar(header string, indent int) (interface{}, error) {
+	literal := header[0] == '|'
+	chomp := byte(0)
+	explicit := 0
+	for _, c := range header[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			explicit = int(c - '0')
+		case c == ' ':
+		default:
+			return nil, p.errorf("bad block scalar header %q", header)
+		}
+	}
+
+	blockIndent := 0
+	if explicit > 0 {
+		blockIndent = indent + 1 + explicit
+		if indent < 0 {
+			blockIndent = explicit
+		}
+	}
+	var lines []string
+	for p.pos < len(p.lines) {
+		raw := p.lines[p.pos].raw
+		trimmed := strings.TrimLeft(raw, " ")
+		ind := len(raw) - len(trimmed)
+		if trimmed == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if blockIndent == 0 {
+			if ind <= indent {
+				break
+			}
+			blockIndent = ind
+		}
+		if ind < blockIndent {
+			break
+		}
+		lines = append(lines, raw[blockIndent:])
+		p.pos++
+	}
+
+	// Trailing blank lines belong to chomping, not to the content
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	trailing := len(lines) - end
+	lines = lines[:end]
+
+	var b strings.Builder
+	if literal {
+		b.WriteString(strings.Join(lines, "\n"))
+	} else {
+		// A line break folds into a space; blank lines stand for themselves
+		// (the break before them is dropped) and more-indented lines keep
+		// their breaks
+		moreIndented := func(s string) bool { return strings.HasPrefix(s, " ") }
+		lastText := ""
+		for i, l := range lines {
+			switch {
+			case i == 0:
+			case l == "":
+				b.WriteByte('\n')
+			case moreIndented(l) || moreIndented(lines[i-1]):
+				b.WriteByte('\n')
+			case lines[i-1] == "":
+				if moreIndented(lastText) {
+					b.WriteByte('\n')
+				}
+			default:
+				b.WriteByte(' ')
+			}
+			b.WriteString(l)
+			if l != "" {
+				lastText = l
+			}
+		}
+	}
+
+	out := b.String()
+	switch chomp {
+	case '-':
+	case '+':
+		out += "\n" + strings.Repeat("\n", trailing)
+	default:
+		if len(lines) > 0 {
+			out += "\n"
+		}
+	}
+	return out, nil
+}
+
+// flowValue parses a [..] or {..} collection, reading more lines until
+// the brackets are balanced.
+func (p *parser) flowValue(rest string) (interface{}, error) {
+	text := rest
+	for !balanced(text) {
+		if p.pos >= len(p.lines) {
+			return nil, p.errorf("unterminated flow collection")
+		}
+		text += " " + strings.TrimSpace(p.lines[p.pos].text)
+		p.pos++
+	}
+	f := &flowParser{s: text, anchors: p.anchors}
+	v, err := f.value()
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	f.space()
+	if f.i < len(f.s) {
+		return nil, p.errorf("unexpected text after flow collection: %q", f.s[f.i:])
+	}
+	return v, nil
+}
+
+func balanced(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\'':
+			end := closingQuote(s[i:], c)
+			if end < 0 {
+				return false
+			}
+			i += end
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+type flowParser struct {
+	s       string
+	i       int
+	anchors map[string]interface{}
+}
+
+func (f *flowParser) space() {
+	for f.i < len(f.s) && (f.s[f.i] == ' ' || f.s[f.i] == '\t') {
+		f.i++
+	}
+}
+
+func (f *flowParser) value() (interface{}, error) {
+	f.space()
+	if f.i >= len(f.s) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch c := f.s[f.i]; c {
+	case '[':
+		f.i++
+		out := []interface{}{}
+		for {
+			f.space()
+			if f.i < len(f.s) && f.s[f.i] == ']' {
+				f.i++
+				return out, nil
+			}
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			if err := f.separator(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.i++
+		out := map[string]interface{}{}
+		for {
+			f.space()
+			if f.i < len(f.s) && f.s[f.i] == '}' {
+				f.i++
+				return out, nil
+			}
+			k, err := f.scalar(true)
+			if err != nil {
+				return nil, err
+			}
+			key := fmt.Sprint(k)
+			if k == nil {
+				key = ""
+			}
+			f.space()
+			var v interface{}
+			if f.i < len(f.s) && f.s[f.i] == ':' {
+				f.i++
+				if v, err = f.value(); err != nil {
+					return nil, err
+				}
+			}
+			out[key] = v
+			if err := f.separator('}'); err != nil {
+				return nil, err
+			}
+		}
+	case '*':
+		end := f.i + 1
+		for end < len(f.s) && strings.IndexByte(" ,]}", f.s[end]) < 0 {
+			end++
+		}
+		a, ok := f.anchors[f.s[f.i+1:end]]
+		if !ok {
+			return nil, fmt.Errorf("unknown alias %q", f.s[f.i:end])
+		}
+		f.i = end
+		return a, nil
+	}
+	return f.scalar(false)
+}
+
+// separator consumes "," or peeks the closing bracket.
+func (f *flowParser) separator(closing byte) error {
+	f.space()
+	if f.i >= len(f.s) {
+		return fmt.Errorf("missing %q", closing)
+	}
+	switch f.s[f.i] {
+	case ',':
+		f.i++
+		return nil
+	case closing:
+		return nil
+	}
+	return fmt.Errorf("expected ',' or %q at %q", closing, f.s[f.i:])
+}
+
+// scalar reads a quoted or plain scalar inside a flow collection. Keys
+// stop at ':' and are never typed.
+func (f *flowParser) scalar(key bool) (interface{}, error) {
+	f.space()
+	if c := f.s[f.i]; c == '"' || c == '\'' {
+		end := closingQuote(f.s[f.i:], c)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated quoted string")
+		}
+		s, err := unquote(f.s[f.i : f.i+end+1])
+		f.i += end + 1
+		return s, err
+	}
+	start := f.i
+	for f.i < len(f.s) {
+		c := f.s[f.i]
+		if c == ',' || c == ']' || c == '}' {
+			break
+		}
+		if c == ':' && (f.i+1 == len(f.s) || strings.IndexByte(" ,]}", f.s[f.i+1]) >= 0) {
+			break
+		}
+		f.i++
+	}
+	text := strings.TrimSpace(f.s[start:f.i])
+	if key {
+		return text, nil
+	}
+	return resolvePlain(text), nil
+}
@@ -0,0 +1,74 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", `null`},
+		{"scalars", "s: text\ni: 42\nf: 1.5\nt: true\nn: null\ntilde: ~", `{"f":1.5,"i":42,"n":null,"s":"text","t":true,"tilde":null}`},
+		{"quoted", `a: "x: #1"` + "\nb: 'it''s'\nc: \"42\"", `{"a":"x: #1","b":"it's","c":"42"}`},
+		{"comments", "# head\na: 1 # trailing\nb: x#y\n", `{"a":1,"b":"x#y"}`},
+		{"nested map", "a:\n  b:\n    c: 1\n  d: 2", `{"a":{"b":{"c":1},"d":2}}`},
+		{"sequence", "- 1\n- two\n-\n  - 3", `[1,"two",[3]]`},
+		{"sequence of maps", "- name: a\n  id: 1\n- name: b", `[{"id":1,"name":"a"},{"name":"b"}]`},
+		{"same-indent list", "items:\n- a\n- b\nnext: 1", `{"items":["a","b"],"next":1}`},
+		{"flow", "a: [1, b, {c: d}]\ne: {}", `{"a":[1,"b",{"c":"d"}],"e":{}}`},
+		{"literal", "a: |\n  line 1\n  line 2\nb: 1", `{"a":"line 1\nline 2\n","b":1}`},
+		{"literal strip", "a: |-\n  x\n\n", `{"a":"x"}`},
+		{"folded", "a: >\n  one\n  two\n\n  three\n", `{"a":"one two\nthree\n"}`},
+		{"folded blank lines", "a: >-\n  one\n\n\n  two", `{"a":"one\n\ntwo"}`},
+		{"folded more-indented", "a: >\n  text\n    * one\n\n    * two\n\n  last\n", `{"a":"text\n  * one\n\n  * two\n\nlast\n"}`},
+		{"tab inside block scalar", "a: |\n  x\n  \ty\n", `{"a":"x\n\ty\n"}`},
+		{"multi-line plain", "a: one\n  two", `{"a":"one two"}`},
+		{"anchor and alias", "base: &b {x: 1}\nuse: *b", `{"base":{"x":1},"use":{"x":1}}`},
+		{"merge key", "base: &b\n  x: 1\n  y: 2\nuse:\n  <<: *b\n  y: 3", `{"base":{"x":1,"y":2},"use":{"x":1,"y":3}}`},
+		{"document marker", "---\na: 1\n...", `{"a":1}`},
+		{"crlf", "a: 1\r\nb: 2\r\n", `{"a":1,"b":2}`},
+		{"tab-only comment line", "a:\n  b: 1\n\t# note\n", `{"a":{"b":1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToJSON([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("ToJSON(%q): %v", tt.in, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToJSON(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string // substring of the error
+	}{
+		{"tab indentation", "a:\n\tb: 1", "line 2: tab character in indentation"},
+		{"tab in sequence", "- a\n\t- b", "line 2: tab character in indentation"},
+		{"tab after spaces", "a:\n  - 1\n \t- 2", "line 3: tab character in indentation"},
+		{"unexpected indentation", "a:\n  b: 1\n c: 2", "unexpected indentation"},
+		{"item in mapping", "a: 1\n- b", "sequence item where a mapping key was expected"},
+		{"not a key", "a: 1\nb", "expected 'key: value'"},
+		{"unclosed flow", "a: [1, 2", "line 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToJSON([]byte(tt.in))
+			if err == nil {
+				t.Fatalf("ToJSON(%q) = %s, want an error", tt.in, got)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ToJSON(%q) error = %q, want it to contain %q", tt.in, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// node is an ordered, decoded JSON value.
+type node struct {
+	kind   byte // 'o' object, 'a' array, 's' string, 'n' number, 'b' bool, 'z' null
+	keys   []string
+	values []*node
+	scalar string
+}
+
+// FromJSON converts a JSON document to YAML, keeping object key order.
+func FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	n, err := decodeNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	var buf bytes.Buffer
+	writeNode(&buf, n, 0, false)
+	return buf.Bytes(), nil
+}
+
+// Marshal converts a value to YAML via its JSON encoding. Struct field
+// order is kept; map keys come out sorted (as encoding/json sorts them).
+func Marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(data)
+}
+
+func decodeNode(dec *json.Decoder) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n := &node{kind: 'o'}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := decodeNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, kt.(string))
+				n.values = append(n.values, v)
+			}
+			_, err := dec.Token() // }
+			return n, err
+		case '[':
+			n := &node{kind: 'a'}
+			for dec.More() {
+				v, err := decodeNode(dec)
+				if err != nil {
+					return nil, err
+				}
+				n.values = append(n.values, v)
+			}
+			_, err := dec.Token() // ]
+			return n, err
+		}
+	case string:
+		return &node{kind: 's', scalar: t}, nil
+	case json.Number:
+		return &node{kind: 'n', scalar: t.String()}, nil
+	case bool:
+		return &node{kind: 'b', scalar: strconv.FormatBool(t)}, nil
+	case nil:
+		return &node{kind: 'z', scalar: "null"}, nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+// writeNode writes n at the given indent. inline means the caller already
+// wrote a "- " or "key: " prefix on the current line.
+func writeNode(buf *bytes.Buffer, n *node, indent int, inline bool) {
+	pad := strings.Repeat("  ", indent)
+	switch n.kind {
+	case 'o':
+		if len(n.keys) == 0 {
+			buf.WriteString("{}\n")
+			return
+		}
+		if inline {
+			buf.WriteString("\n")
+		}
+		for i, k := range n.keys {
+			buf.WriteString(pad)
+			buf.WriteString(quoteString(k))
+			buf.WriteString(":")
+			writeChild(buf, n.values[i], indent)
+		}
+	case 'a':
+		if len(n.values) == 0 {
+			buf.WriteString("[]\n")
+			return
+		}
+		if inline {
+			buf.WriteString("\n")
+		}
+		for _, v := range n.values {
+			if v.kind == 'o' && len(v.keys) > 0 {
+				// "- key: value" with the remaining keys aligned below it
+				var item bytes.Buffer
+				writeNode(&item, v, indent+1, false)
+				buf.WriteString(pad)
+				buf.WriteString("- ")
+				buf.Write(item.Bytes()[len(pad)+2:])
+				continue
+			}
+			buf.WriteString(pad)
+			buf.WriteString("-")
+			writeChild(buf, v, indent)
+		}
+	default:
+		buf.WriteString(scalarText(n))
+		buf.WriteString("\n")
+	}
+}
+
+func writeChild(buf *bytes.Buffer, v *node, indent int) {
+	switch {
+	case v.kind == 'o' && len(v.keys) > 0, v.kind == 'a' && len(v.values) > 0:
+		writeNode(buf, v, indent+1, true)
+	case v.kind == 's' && strings.Contains(v.scalar, "\n"):
+		// Literal block scalar for multi-line strings
+		buf.WriteString(" |")
+		if !strings.HasSuffix(v.scalar, "\n") {
+			buf.WriteString("-")
+		}
+		buf.WriteString("\n")
+		pad := strings.Repeat("  ", indent+1)
+		for _, line := range strings.Split(strings.TrimSuffix(v.scalar, "\n"), "\n") {
+			if line != "" {
+				buf.WriteString(pad)
+			}
+			buf.WriteString(line)
+			buf.WriteString("\n")
+		}
+	default:
+		buf.WriteString(" ")
+		writeNode(buf, v, indent+1, true)
+	}
+}
+
+func scalarText(n *node) string {
+	if n.kind != 's' {
+		return n.scalar
+	}
+	return quoteString(n.scalar)
+}
+
+// quoteString returns s as a plain scalar when that is unambiguous, or as a
+// double-quoted string otherwise.
+func quoteString(s string) string {
+	if needsQuotes(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+var reserved = map[string]bool{
+	"": true, "~": true, "null": true, "true": true, "false": true, "yes": true, "no": true,
+	"on": true, "off": true, "y": true, "n": true,
+}
+
+func needsQuotes(s string) bool {
+	if reserved[strings.ToLower(s)] {
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.TrimSpace(s) != s {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
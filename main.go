@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"go-rest-api-cli-demo/internal/command"
 	"go-rest-api-cli-demo/internal/httpclient"
@@ -11,8 +12,18 @@ func main() {
 	reg := command.NewRegistry()
 
 	factory := httpclient.Factory{}
-	reg.Register(command.NewCallCommand(factory))
+	call := command.NewCallCommand(factory)
+	reg.Register(call)
 	reg.Register(command.NewProfileCommand())
+	reg.Register(command.NewRequestCommand(call))
+	reg.Register(command.NewOpenAPICommand(call))
+	reg.Register(command.NewRunCommand(factory))
+	reg.Register(command.NewBatchCommand(factory))
+	reg.Register(command.NewTestCommand(factory))
+	reg.Register(command.NewWSCommand(factory))
+	reg.Register(command.NewGraphQLCommand(factory))
+	reg.Register(command.NewRPCCommand(factory))
+	reg.Register(command.NewDiffCommand(factory))
 	reg.Register(command.NewInspectCommand())
 	reg.Register(command.NewHelpCommand(reg, "go-rest-api-cli-demo"))
 
@@ -35,6 +46,10 @@ func main() {
 
 	if err := cmd.Run(os.Args[2:]); err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
+		var exitErr *command.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }